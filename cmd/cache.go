@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var (
+	cacheDir       string
+	cacheOlderThan string
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local build history cache",
+}
+
+var cacheSyncCmd = &cobra.Command{
+	Use:   "sync <job|folder>",
+	Short: "Fetch completed builds of a job, or of every job in a folder, into the local cache",
+	Args:  cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		injectViperFlags(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		bc, err := jenkins.NewBuildCache(cacheDir)
+		cobra.CheckErr(err)
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.SyncBuildCache(jenkinsClient, bc, args[0]))
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached builds older than the specified age",
+	Run: func(cmd *cobra.Command, args []string) {
+		bc, err := jenkins.NewBuildCache(cacheDir)
+		cobra.CheckErr(err)
+		cobra.CheckErr(jenkins.PruneBuildCache(bc, url, cacheOlderThan))
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Output the number of cached jobs and builds per jenkins instance",
+	Run: func(cmd *cobra.Command, args []string) {
+		bc, err := jenkins.NewBuildCache(cacheDir)
+		cobra.CheckErr(err)
+		cobra.CheckErr(jenkins.PrintBuildCacheStats(bc))
+	},
+}
+
+func init() {
+	cacheCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Directory of the build cache (default is tronci in the user cache directory, e.g. $HOME/.cache/tronci on Linux)")
+
+	cacheSyncCmd.Flags().StringVar(&url, "url", "", "URL of the Jenkins host (required)")
+	cacheSyncCmd.Flags().StringVar(&user, "user", "", "Jenkins username (required)")
+	cacheSyncCmd.Flags().StringVar(&apiToken, "api-token", "", "Jenkins API token (required)")
+	cacheSyncCmd.Flags().BoolVarP(&enableDebug, "debug", "v", false, "Enable debug output")
+	cacheSyncCmd.MarkFlagRequired("url")
+	cacheSyncCmd.MarkFlagRequired("user")
+	cacheSyncCmd.MarkFlagRequired("api-token")
+
+	cachePruneCmd.Flags().StringVar(&cacheOlderThan, "older-than", "", "Remove builds scheduled before this age or date (required), e.g. \"90d\" or \"2026-01-01\"")
+	cachePruneCmd.Flags().StringVar(&url, "url", "", "Only prune builds of this Jenkins host")
+	cachePruneCmd.MarkFlagRequired("older-than")
+
+	cacheCmd.AddCommand(cacheSyncCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
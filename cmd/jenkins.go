@@ -32,6 +32,7 @@ var (
 	buildID        int64
 	enableDebug    bool
 	decryptSecrets bool
+	jenkinsContext string
 	jenkinsClient  *jenkins.APIClient
 )
 
@@ -112,6 +113,7 @@ func init() {
 	jenkinsCmd.PersistentFlags().StringVar(&user, "user", "", "Jenkins username (required)")
 	jenkinsCmd.PersistentFlags().StringVar(&apiToken, "api-token", "", "Jenkins API token (required)")
 	jenkinsCmd.PersistentFlags().BoolVarP(&enableDebug, "debug", "v", false, "Enable debug output")
+	jenkinsCmd.PersistentFlags().StringVar(&jenkinsContext, "context", "", "Name of a jenkins instance defined under jenkins.contexts in the config file")
 	jenkinsCmd.MarkPersistentFlagRequired("url")
 	jenkinsCmd.MarkPersistentFlagRequired("user")
 	jenkinsCmd.MarkPersistentFlagRequired("api-token")
@@ -127,16 +129,84 @@ func init() {
 	jenkinsCmd.AddCommand(pluginCmd)
 	jenkinsCmd.AddCommand(getCmd)
 	jenkinsCmd.AddCommand(restartCmd)
+	jenkinsCmd.AddCommand(statsCmd)
+	jenkinsCmd.AddCommand(usageCmd)
+	jenkinsCmd.AddCommand(buildGroupCmd)
+	jenkinsCmd.AddCommand(buildsCmd)
+	jenkinsCmd.AddCommand(jobsCmd)
+	jenkinsCmd.AddCommand(jobCmd)
+	jenkinsCmd.AddCommand(folderCmd)
+	jenkinsCmd.AddCommand(syncCmd)
+	jenkinsCmd.AddCommand(migrateCmd)
+	jenkinsCmd.AddCommand(inputCmd)
+	jenkinsCmd.AddCommand(lintJenkinsfileCmd)
+	jenkinsCmd.AddCommand(multibranchCmd)
+	jenkinsCmd.AddCommand(webhookCmd)
 }
 
+/*
+	Sets the flags that were not given on the command line from the config file: first from the selected
+	context under jenkins.contexts, if any, then from the jenkins section.
+*/
 func injectViperFlags(cmd *cobra.Command) {
-	vcfg := viper.Sub("jenkins")
+	var configs []*viper.Viper
+	if jenkinsContext != "" {
+		ccfg, err := contextConfig(jenkinsContext)
+		cobra.CheckErr(err)
+		configs = append(configs, ccfg)
+	}
+	if vcfg := viper.Sub("jenkins"); vcfg != nil {
+		configs = append(configs, vcfg)
+	}
 
-	if vcfg != nil {
+	for _, cfg := range configs {
 		cmd.Flags().VisitAll(func(f *pflag.Flag) {
-			if !f.Changed && vcfg.IsSet(f.Name) {
-				cmd.Flags().Set(f.Name, vcfg.GetString(f.Name))
+			if !f.Changed && cfg.IsSet(f.Name) {
+				cmd.Flags().Set(f.Name, cfg.GetString(f.Name))
 			}
 		})
 	}
 }
+
+/*
+	Sets the flags that were not given on the command line from a context, for commands that work with the
+	instances of contexts rather than the one given by --url, which the jenkins command requires nonetheless.
+*/
+func injectContextFlags(cmd *cobra.Command, name string) {
+	if jenkinsContext == "" && name != "" {
+		jenkinsContext = name
+		injectViperFlags(cmd)
+	}
+}
+
+/*
+	Returns the settings of a named jenkins instance from the config file, e.g.
+
+	  jenkins:
+	    contexts:
+	      staging:
+	        url: https://jenkins-staging.example.com/
+	        user: jdoe
+	        api-token: ...
+*/
+func contextConfig(name string) (*viper.Viper, error) {
+	ccfg := viper.Sub("jenkins.contexts." + name)
+	if ccfg == nil || !ccfg.IsSet("url") {
+		return nil, fmt.Errorf("context \"%s\" is not defined under jenkins.contexts in the config file", name)
+	}
+	return ccfg, nil
+}
+
+// Returns a client for a named jenkins instance from the config file
+func newContextClient(name string) (*jenkins.APIClient, error) {
+	ccfg, err := contextConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	jenkinsCreds := jenkins.Credentials{
+		Username: ccfg.GetString("user"),
+		APIToken: ccfg.GetString("api-token"),
+	}
+	return jenkins.NewJenkinsClient(ccfg.GetString("url"), jenkinsCreds, enableDebug), nil
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var (
+	buildSetID          int64
+	buildSetDescription string
+	buildSetDisplayName string
+	buildSetKeepForever bool
+	buildTriggerOpts    jenkins.TriggerOptions
+	buildMatrixOpts     jenkins.MatrixOptions
+	buildRerunID        int64
+	buildReplayOpts     jenkins.ReplayOptions
+	buildRestartOpts    jenkins.RestartOptions
+)
+
+var buildGroupCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Manage individual builds",
+}
+
+var buildSetCmd = &cobra.Command{
+	Use:   "set <job>",
+	Short: "Set the description, display name or keep-forever flag of a build",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var meta jenkins.BuildMetadata
+		if cmd.Flags().Changed("description") {
+			meta.Description = &buildSetDescription
+		}
+		if cmd.Flags().Changed("display-name") {
+			meta.DisplayName = &buildSetDisplayName
+		}
+		if cmd.Flags().Changed("keep-forever") {
+			meta.KeepForever = &buildSetKeepForever
+		}
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.SetBuildMetadata(jenkinsClient, args[0], buildSetID, meta))
+	},
+}
+
+var buildTriggerCmd = &cobra.Command{
+	Use:   "trigger <job>",
+	Short: "Trigger a build of a job, validating its parameters first",
+	Long: `Trigger a build of a job, validating its parameters first.
+
+Parameter values given with -p are checked against the parameter definitions of the job before the build is
+triggered: unknown names, values that are not one of the choices of a choice parameter, and boolean values other
+than true or false are rejected. Parameters that are not given take their default values.
+With --wait, the command waits for the build to complete and fails unless it succeeds; --follow also streams the
+console output of the build. A build that is still queued after 30 minutes is reported as an error.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.TriggerBuild(jenkinsClient, args[0], buildTriggerOpts))
+	},
+}
+
+var buildMatrixCmd = &cobra.Command{
+	Use:   "matrix <job>",
+	Short: "Trigger a build of a job for every combination of parameter values",
+	Long: `Trigger a build of a job for every combination of parameter values.
+
+The axes file maps parameter names to lists of values, and a build is triggered for each combination:
+
+  OS: [linux, windows]
+  JDK: [11, 17]
+
+To leave out some combinations, put the axes under an "axes" key and list the combinations under "exclude":
+
+  axes:
+    OS: [linux, windows]
+    JDK: [11, 17]
+  exclude:
+    - {OS: windows, JDK: 11}
+
+Parameters given with -p are passed to every build. All combinations are validated against the parameter
+definitions of the job before any build is triggered. At most --max-concurrent builds are tracked at once: each
+build is tracked until it starts or, with --wait, until it completes; builds still queued after 30 minutes are
+reported as errors. A table of the builds with their result, duration and link is printed at the end.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.TriggerBuildMatrix(jenkinsClient, args[0], buildMatrixOpts))
+	},
+}
+
+var buildReplayCmd = &cobra.Command{
+	Use:   "replay <job>",
+	Short: "Replay a pipeline build with a modified pipeline script",
+	Long: `Replay a pipeline build with a modified pipeline script.
+
+The script read from --jenkinsfile replaces the main script of the build, so that changes to a pipeline can be tried
+without committing them. Scripts loaded by the pipeline with the load step are replayed unchanged. With --wait,
+the command waits for the new build to complete and fails unless it succeeds; --follow also streams its console
+output.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ReplayBuild(jenkinsClient, args[0], buildRerunID, buildReplayOpts))
+	},
+}
+
+var buildRestartCmd = &cobra.Command{
+	Use:   "restart <job>",
+	Short: "Restart a declarative pipeline build from a stage",
+	Long: `Restart a declarative pipeline build from a stage.
+
+The stages before --stage are skipped. The build must be complete, and the job must allow restarting from stages.
+With --wait, the command waits for the new build to complete and fails unless it succeeds; --follow also streams its
+console output.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.RestartBuildFromStage(jenkinsClient, args[0], buildRerunID, buildRestartOpts))
+	},
+}
+
+func init() {
+	buildSetCmd.Flags().Int64Var(&buildSetID, "id", 0, "ID of the target build (required), e.g. 22")
+	buildSetCmd.Flags().StringVar(&buildSetDescription, "description", "", "New build description")
+	buildSetCmd.Flags().StringVar(&buildSetDisplayName, "display-name", "", "New build display name")
+	buildSetCmd.Flags().BoolVar(&buildSetKeepForever, "keep-forever", false, "Protect the build from log rotation, or remove the protection with --keep-forever=false")
+	buildSetCmd.MarkFlagRequired("id")
+
+	buildGroupCmd.AddCommand(buildSetCmd)
+
+	buildTriggerCmd.Flags().StringArrayVarP(&buildTriggerOpts.Params, "param", "p", nil, "Parameter value as KEY=VALUE (repeatable)")
+	buildTriggerCmd.Flags().BoolVar(&buildTriggerOpts.Wait, "wait", false, "Wait for the build to complete and fail unless it succeeds")
+	buildTriggerCmd.Flags().BoolVarP(&buildTriggerOpts.Follow, "follow", "f", false, "Wait for the build and stream its console output")
+
+	buildGroupCmd.AddCommand(buildTriggerCmd)
+
+	buildMatrixCmd.Flags().StringVar(&buildMatrixOpts.Axes, "axes", "", "YAML file of the parameter values to combine (required)")
+	buildMatrixCmd.Flags().StringArrayVarP(&buildMatrixOpts.Params, "param", "p", nil, "Parameter value as KEY=VALUE passed to every build (repeatable)")
+	buildMatrixCmd.Flags().IntVar(&buildMatrixOpts.MaxConcurrent, "max-concurrent", 5, "Maximum number of builds tracked at once")
+	buildMatrixCmd.Flags().BoolVar(&buildMatrixOpts.Wait, "wait", false, "Wait for every build to complete and fail unless they all succeed")
+	buildMatrixCmd.MarkFlagRequired("axes")
+
+	buildGroupCmd.AddCommand(buildMatrixCmd)
+
+	for _, c := range []*cobra.Command{buildReplayCmd, buildRestartCmd} {
+		c.Flags().Int64Var(&buildRerunID, "id", 0, "ID of the build to rerun (required), e.g. 22")
+		c.MarkFlagRequired("id")
+	}
+	buildReplayCmd.Flags().StringVar(&buildReplayOpts.Jenkinsfile, "jenkinsfile", "", "Path of the pipeline script to replay the build with (required)")
+	buildReplayCmd.Flags().BoolVar(&buildReplayOpts.Wait, "wait", false, "Wait for the new build to complete and fail unless it succeeds")
+	buildReplayCmd.Flags().BoolVarP(&buildReplayOpts.Follow, "follow", "f", false, "Wait for the new build and stream its console output")
+	buildReplayCmd.MarkFlagRequired("jenkinsfile")
+	buildRestartCmd.Flags().StringVar(&buildRestartOpts.Stage, "stage", "", "Name of the stage to restart from (required)")
+	buildRestartCmd.Flags().BoolVar(&buildRestartOpts.Wait, "wait", false, "Wait for the new build to complete and fail unless it succeeds")
+	buildRestartCmd.Flags().BoolVarP(&buildRestartOpts.Follow, "follow", "f", false, "Wait for the new build and stream its console output")
+	buildRestartCmd.MarkFlagRequired("stage")
+
+	buildGroupCmd.AddCommand(buildReplayCmd)
+	buildGroupCmd.AddCommand(buildRestartCmd)
+
+	buildsPruneCmd.Flags().IntVar(&pruneOpts.KeepLast, "keep-last", 50, "Number of most recent builds to keep per job")
+	buildsPruneCmd.Flags().IntVar(&pruneOpts.KeepDays, "keep-days", 0, "Keep builds younger than this many days (0 disables)")
+	buildsPruneCmd.Flags().StringSliceVar(&pruneOpts.KeepResults, "keep-results", nil, "Keep builds with these results, e.g. \"SUCCESS,UNSTABLE\"")
+	buildsPruneCmd.Flags().BoolVar(&pruneOpts.DryRun, "dry-run", false, "Only print the builds that would be deleted")
+	buildsPruneCmd.Flags().BoolVarP(&pruneOpts.Yes, "yes", "y", false, "Delete without asking for confirmation")
+	buildsPruneCmd.Flags().IntVar(&pruneOpts.Concurrency, "concurrency", 4, "Maximum number of simultaneous delete requests")
+
+	buildsCmd.AddCommand(buildsPruneCmd)
+}
+
+var pruneOpts jenkins.PruneOptions
+
+var buildsCmd = &cobra.Command{
+	Use:   "builds",
+	Short: "Manage the builds of many jobs at once",
+}
+
+var buildsPruneCmd = &cobra.Command{
+	Use:   "prune <job|folder>",
+	Short: "Delete old builds not retained by the specified policy",
+	Long: `Delete old builds not retained by the specified policy.
+
+A build is retained if it is one of the --keep-last most recent builds, if it is younger than --keep-days,
+if its result is listed in --keep-results, if it is marked keep-forever, or if it is still running. The builds to
+delete are listed and confirmed before any is deleted, unless --yes is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.PruneBuilds(jenkinsClient, args[0], pruneOpts))
+	},
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var (
+	folderOpts            jenkins.FolderOptions
+	folderListRecursive   bool
+	folderDeleteRecursive bool
+	folderDeleteYes       bool
+)
+
+var folderCmd = &cobra.Command{
+	Use:   "folder",
+	Short: "Create, list and delete folders",
+}
+
+var folderCreateCmd = &cobra.Command{
+	Use:   "create <folder>",
+	Short: "Create a folder, e.g. a/b/c, with its description, health metric and shared libraries",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.CreateFolder(jenkinsClient, args[0], folderOpts))
+	},
+}
+
+var folderListCmd = &cobra.Command{
+	Use:   "list [folder]",
+	Short: "List the folders in a folder (default is the root of the jenkins instance)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		folder := ""
+		if len(args) > 0 {
+			folder = args[0]
+		}
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ListFolders(jenkinsClient, folder, folderListRecursive))
+	},
+}
+
+var folderDeleteCmd = &cobra.Command{
+	Use:   "delete <folder>",
+	Short: "Delete a folder; --recursive is required if it is not empty",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.DeleteFolder(jenkinsClient, args[0], folderDeleteRecursive, folderDeleteYes))
+	},
+}
+
+func init() {
+	folderCreateCmd.Flags().BoolVarP(&folderOpts.Parents, "parents", "p", false, "Create missing parent folders, and do not fail if the folder exists")
+	folderCreateCmd.Flags().StringVarP(&folderOpts.Description, "description", "d", "", "Description of the folder")
+	folderCreateCmd.Flags().StringVar(&folderOpts.HealthMetric, "health-metric", "worst-child", "Health metric of the folder: worst-child or none")
+	folderCreateCmd.Flags().BoolVar(&folderOpts.HealthNonRecursive, "health-non-recursive", false, "Only consider direct children for the worst-child health metric")
+	folderCreateCmd.Flags().StringArrayVar(&folderOpts.Libraries, "library", nil, "Shared library allowed in the folder as name=git-url[@default-version] (repeatable)")
+
+	folderListCmd.Flags().BoolVarP(&folderListRecursive, "recursive", "r", false, "Include nested folders")
+
+	folderDeleteCmd.Flags().BoolVarP(&folderDeleteRecursive, "recursive", "r", false, "Delete the folder along with the jobs and folders in it")
+	folderDeleteCmd.Flags().BoolVarP(&folderDeleteYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	folderCmd.AddCommand(folderCreateCmd)
+	folderCmd.AddCommand(folderListCmd)
+	folderCmd.AddCommand(folderDeleteCmd)
+}
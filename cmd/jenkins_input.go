@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var (
+	inputListFolder string
+	inputListOutput string
+	inputBuildID    int64
+	inputResponse   jenkins.InputResponse
+)
+
+var inputCmd = &cobra.Command{
+	Use:   "input",
+	Short: "List and respond to the input steps pipeline builds are paused on",
+}
+
+var inputListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the running pipeline builds that are waiting for input",
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ListPendingInputs(jenkinsClient, inputListFolder, inputListOutput))
+	},
+}
+
+var inputApproveCmd = &cobra.Command{
+	Use:   "approve <job>",
+	Short: "Approve an input step of a pipeline build, with parameter values given as KEY=VALUE",
+	Long: `Approve an input step of a pipeline build, with parameter values given as KEY=VALUE.
+
+The values are checked against the parameters of the input step before it is approved; parameters that are not
+given take their default values. --input-id may be left out if the build is waiting for a single input.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.RespondToInput(jenkinsClient, args[0], inputBuildID, inputResponse))
+	},
+}
+
+var inputAbortCmd = &cobra.Command{
+	Use:   "abort <job>",
+	Short: "Abort an input step of a pipeline build, which aborts the build",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		inputResponse.Abort = true
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.RespondToInput(jenkinsClient, args[0], inputBuildID, inputResponse))
+	},
+}
+
+func init() {
+	inputListCmd.Flags().StringVar(&inputListFolder, "folder", "", "Only list builds of jobs in this folder, recursively (default is the whole jenkins instance)")
+	inputListCmd.Flags().StringVarP(&inputListOutput, "output", "o", "text", "Output format: text or json")
+
+	for _, c := range []*cobra.Command{inputApproveCmd, inputAbortCmd} {
+		c.Flags().Int64Var(&inputBuildID, "id", 0, "ID of the build waiting for input (required), e.g. 22")
+		c.Flags().StringVar(&inputResponse.InputID, "input-id", "", "ID of the input step (default is the only input the build is waiting for)")
+		c.MarkFlagRequired("id")
+	}
+	inputApproveCmd.Flags().StringArrayVarP(&inputResponse.Params, "param", "p", nil, "Parameter value as KEY=VALUE (repeatable)")
+
+	inputCmd.AddCommand(inputListCmd)
+	inputCmd.AddCommand(inputApproveCmd)
+	inputCmd.AddCommand(inputAbortCmd)
+}
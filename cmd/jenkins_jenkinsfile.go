@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var lintJenkinsfileAll bool
+
+var lintJenkinsfileCmd = &cobra.Command{
+	Use:   "lint-jenkinsfile <Jenkinsfile...|dir...>",
+	Short: "Validate declarative Jenkinsfiles with the pipeline linter of the jenkins instance",
+	Long: `Validate declarative Jenkinsfiles with the pipeline linter of the jenkins instance.
+
+Errors are printed as "file:line:column: message". With --all, the arguments are directories (default is the
+current directory) searched recursively for files named Jenkinsfile, Jenkinsfile.* or *.jenkinsfile, skipping
+hidden directories, node_modules and vendor.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if lintJenkinsfileAll {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if lintJenkinsfileAll && len(args) == 0 {
+			args = []string{"."}
+		}
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.LintJenkinsfiles(jenkinsClient, args, lintJenkinsfileAll))
+	},
+}
+
+func init() {
+	lintJenkinsfileCmd.Flags().BoolVar(&lintJenkinsfileAll, "all", false, "Validate every Jenkinsfile found in the directories given as arguments")
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var (
+	jobConfigFile   string
+	jobConfigApply  bool
+	jobCreateFile   string
+	jobDeleteYes    bool
+	jobTemplateOpts jenkins.JobTemplateOptions
+	jobParamsOutput string
+)
+
+var jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Manage a single job",
+}
+
+var jobConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get or apply the config.xml of a job",
+}
+
+var jobConfigGetCmd = &cobra.Command{
+	Use:   "get <job>",
+	Short: "Output the config.xml of a job",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.GetJobConfig(jenkinsClient, args[0]))
+	},
+}
+
+var jobConfigApplyCmd = &cobra.Command{
+	Use:   "apply <job>",
+	Short: "Show the difference between a config.xml file and the current config of a job, and apply it with --yes",
+	Long: `Show the difference between a config.xml file and the current config of a job, and apply it with --yes.
+
+Both configs are normalized before being compared, so differences in formatting and attribute order are ignored.
+The job is created if it does not exist.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ApplyJobConfig(jenkinsClient, args[0], jobConfigFile, jobConfigApply))
+	},
+}
+
+var jobCreateCmd = &cobra.Command{
+	Use:   "create <job>",
+	Short: "Create a job from a config.xml file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.CreateJob(jenkinsClient, args[0], jobCreateFile))
+	},
+}
+
+var jobCopyCmd = &cobra.Command{
+	Use:   "copy <source job> <new job>",
+	Short: "Create a job as a copy of an existing job",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.CopyJob(jenkinsClient, args[0], args[1]))
+	},
+}
+
+var jobRenameCmd = &cobra.Command{
+	Use:   "rename <job> <new name>",
+	Short: "Rename a job within its folder",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.RenameJob(jenkinsClient, args[0], args[1]))
+	},
+}
+
+var jobMoveCmd = &cobra.Command{
+	Use:   "move <job|-> <folder>",
+	Short: "Move a job, or a list of jobs read from stdin, into a folder (\"/\" for the root)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs, err := jobArgs(args[:1])
+		cobra.CheckErr(err)
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.MoveJobs(jenkinsClient, jobs, args[1]))
+	},
+}
+
+var jobEnableCmd = &cobra.Command{
+	Use:   "enable <job...|->",
+	Short: "Enable jobs, given as arguments or read from stdin",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs, err := jobArgs(args)
+		cobra.CheckErr(err)
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.SetJobsEnabled(jenkinsClient, jobs, true))
+	},
+}
+
+var jobDisableCmd = &cobra.Command{
+	Use:   "disable <job...|->",
+	Short: "Disable jobs, given as arguments or read from stdin",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs, err := jobArgs(args)
+		cobra.CheckErr(err)
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.SetJobsEnabled(jenkinsClient, jobs, false))
+	},
+}
+
+var jobDeleteCmd = &cobra.Command{
+	Use:   "delete <job...|->",
+	Short: "Delete jobs, given as arguments or read from stdin",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs, err := jobArgs(args)
+		cobra.CheckErr(err)
+		if args[0] == "-" && !jobDeleteYes {
+			cobra.CheckErr("--yes is required when reading jobs from stdin")
+		}
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.DeleteJobs(jenkinsClient, jobs, jobDeleteYes))
+	},
+}
+
+var jobParamsCmd = &cobra.Command{
+	Use:   "params <job>",
+	Short: "List the parameters of a job with their type, default value, choices and description",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ListJobParameters(jenkinsClient, args[0], jobParamsOutput))
+	},
+}
+
+var jobRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a config.xml template with the values of one or more jobs",
+	Long: `Render a config.xml template with the values of one or more jobs.
+
+Templates use Go text/template syntax, with the functions xml (escape a value for XML text), default, required,
+join, lower and upper. A values file describes a single job, or several jobs under a "jobs" key, each merged over
+the optional "defaults":
+
+  defaults:
+    team: payments
+    branch: main
+  jobs:
+    - name: payments/checkout
+      repo: https://github.example.com/payments/checkout.git
+    - name: payments/refunds
+      repo: https://github.example.com/payments/refunds.git
+      branch: release
+
+The "name" value is the full name of the job, required by apply-template. Rendering fails if the template refers
+to a value that is not set; optional values are read with index, e.g. {{ default "main" (index . "branch") }}.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cobra.CheckErr(jenkins.RenderJobTemplate(jobTemplateOpts))
+	},
+}
+
+var jobApplyTemplateCmd = &cobra.Command{
+	Use:   "apply-template",
+	Short: "Create or update the jobs described by values files from a config.xml template",
+	Long: `Create or update the jobs described by values files from a config.xml template.
+
+The difference between each rendered config and the current config of the job is shown, followed by a summary of
+the jobs to create and update. The changes are applied with --yes. See "job render" for the template and values
+file formats.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ApplyJobTemplate(jenkinsClient, jobTemplateOpts))
+	},
+}
+
+func init() {
+	jobConfigApplyCmd.Flags().StringVarP(&jobConfigFile, "file", "f", "", "Path of the config.xml to apply (required), or \"-\" for stdin")
+	jobConfigApplyCmd.Flags().BoolVarP(&jobConfigApply, "yes", "y", false, "Apply the config after showing the difference")
+	jobConfigApplyCmd.MarkFlagRequired("file")
+
+	jobConfigCmd.AddCommand(jobConfigGetCmd)
+	jobConfigCmd.AddCommand(jobConfigApplyCmd)
+	jobCmd.AddCommand(jobConfigCmd)
+
+	jobCreateCmd.Flags().StringVarP(&jobCreateFile, "file", "f", "", "Path of the config.xml of the new job (required), or \"-\" for stdin")
+	jobCreateCmd.MarkFlagRequired("file")
+	jobDeleteCmd.Flags().BoolVarP(&jobDeleteYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	jobCmd.AddCommand(jobCreateCmd)
+	jobCmd.AddCommand(jobCopyCmd)
+	jobCmd.AddCommand(jobRenameCmd)
+	jobCmd.AddCommand(jobMoveCmd)
+	jobCmd.AddCommand(jobEnableCmd)
+	jobCmd.AddCommand(jobDisableCmd)
+	jobCmd.AddCommand(jobDeleteCmd)
+
+	jobParamsCmd.Flags().StringVarP(&jobParamsOutput, "output", "o", "text", "Output format: text or json")
+	jobCmd.AddCommand(jobParamsCmd)
+
+	for _, c := range []*cobra.Command{jobRenderCmd, jobApplyTemplateCmd} {
+		c.Flags().StringVarP(&jobTemplateOpts.Template, "template", "t", "", "Path of the config.xml template (required)")
+		c.Flags().StringArrayVarP(&jobTemplateOpts.Values, "values", "f", nil, "Path of a YAML values file (required, repeatable)")
+		c.MarkFlagRequired("template")
+		c.MarkFlagRequired("values")
+	}
+	jobApplyTemplateCmd.Flags().BoolVarP(&jobTemplateOpts.Yes, "yes", "y", false, "Apply the changes after showing the plan")
+
+	jobCmd.AddCommand(jobRenderCmd)
+	jobCmd.AddCommand(jobApplyTemplateCmd)
+}
+
+// Returns the jobs given as arguments, or read one per line from stdin if the only argument is "-"
+func jobArgs(args []string) ([]string, error) {
+	if len(args) != 1 || args[0] != "-" {
+		return args, nil
+	}
+
+	var jobs []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			jobs = append(jobs, line)
+		}
+	}
+
+	return jobs, scanner.Err()
+}
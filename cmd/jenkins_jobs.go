@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var (
+	jobListOpts      jenkins.JobListOptions
+	jobSearchOpts    jenkins.SearchOptions
+	jobTransformOpts jenkins.TransformOptions
+	jobRestoreYes    bool
+	jobLintOpts      jenkins.LintOptions
+	jobLintRules     string
+	jobExportOpts    jenkins.ExportOptions
+	jobDiffOpts      jenkins.JobDiffOptions
+	jobGraphOpts     jenkins.JobGraphOptions
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Query and manage many jobs at once",
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List jobs and folders with the result and time of their last build",
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ListJobs(jenkinsClient, jobListOpts))
+	},
+}
+
+var jobsSearchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search the config.xml of every job for elements matching an xpath expression and/or a regex",
+	Long: `Search the config.xml of every job for elements matching an xpath expression and/or a regex.
+
+The xpath expression selects elements, e.g. //assignedNode or //hudson.tasks.Shell/command, and the regex is
+matched against their text, including the text of nested elements. Without --xpath, the whole config is searched;
+without --regex, every selected element is reported. For example, to find jobs still using the rhel7 label:
+
+  tronci jenkins jobs search --xpath '//assignedNode' --regex 'rhel7'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.SearchJobs(jenkinsClient, jobSearchOpts))
+	},
+}
+
+var jobsTransformCmd = &cobra.Command{
+	Use:   "transform",
+	Short: "Edit the config.xml of many jobs, showing a diff per job before applying the changes",
+	Long: `Edit the config.xml of many jobs, showing a diff per job before applying the changes.
+
+Elements selected by --xpath have their text replaced by --replace, or are replaced entirely if the replacement
+is an XML fragment. With --regex, only the matching parts of the text of the selected elements are replaced, and
+the replacement may refer to submatches as $1. The previous configs are saved to a rollback file before anything
+is changed, and can be restored with "jobs restore". For example:
+
+  tronci jenkins jobs transform --xpath //assignedNode --replace rhel9 --plan
+  tronci jenkins jobs transform --xpath //credentialsId --regex '^deploy-key$' --replace deploy-key-v2
+  tronci jenkins jobs transform --xpath //jenkins.model.BuildDiscarderProperty/strategy \
+    --replace '<strategy class="hudson.tasks.LogRotator"><numToKeepStr>20</numToKeepStr></strategy>'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.TransformJobs(jenkinsClient, jobTransformOpts))
+	},
+}
+
+var jobsRestoreCmd = &cobra.Command{
+	Use:   "restore <rollback file>",
+	Short: "Restore the job configs saved in a rollback file by jobs transform",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.RestoreJobs(jenkinsClient, args[0], jobRestoreYes))
+	},
+}
+
+var jobsLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check job configs for common problems and output the findings as text, JSON or SARIF",
+	Long: `Check job configs for common problems and output the findings as text, JSON or SARIF.
+
+Rules: missing-build-discarder, built-in-node, no-timeout, concurrent-builds, scm-poll-every-minute and
+hardcoded-secret. They can be disabled or have their level changed under jenkins.lint in the config file, or in a
+separate file given with --rules:
+
+  rules:
+    concurrent-builds:
+      enabled: false
+    no-timeout:
+      level: error
+    hardcoded-secret:
+      patterns:
+        - 'MYCORP_[A-Z]+_TOKEN=\S+'
+    built-in-node:
+      labels: [master, built-in, controller]
+
+The command fails if any finding has the "error" level.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cobra.CheckErr(viper.UnmarshalKey("jenkins.lint", &jobLintOpts.Config))
+		if jobLintRules != "" {
+			v := viper.New()
+			v.SetConfigFile(jobLintRules)
+			cobra.CheckErr(v.ReadInConfig())
+			cobra.CheckErr(v.Unmarshal(&jobLintOpts.Config))
+		}
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.LintJobs(jenkinsClient, jobLintOpts))
+	},
+}
+
+var jobsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the jobs and folders in a folder as a Job DSL script or a directory tree of config.xml files",
+	Long: `Export the jobs and folders in a folder as a Job DSL script or a directory tree of config.xml files.
+
+The job-dsl format uses the native methods of folders, pipelines, multibranch pipelines and freestyle jobs where
+possible, and configure blocks for the rest of their config. The xml-tree format writes <out>/<name>/config.xml
+for each item, with names relative to --folder, and can be applied with "sync".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ExportJobs(jenkinsClient, jobExportOpts))
+	},
+}
+
+var jobsDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the jobs of two jenkins instances defined as contexts in the config file",
+	Long: `Compare the jobs of two jenkins instances defined as contexts in the config file.
+
+Jobs and folders that exist on only one side are listed, followed by the normalized config diff of the others.
+Contexts are defined in the config file:
+
+  jenkins:
+    contexts:
+      staging:
+        url: https://jenkins-staging.example.com/
+        user: jdoe
+        api-token: ...
+      prod:
+        url: https://jenkins.example.com/
+        user: jdoe
+        api-token: ...`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		injectContextFlags(cmd, jobDiffOpts.FromName)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := newContextClient(jobDiffOpts.FromName)
+		cobra.CheckErr(err)
+		to, err := newContextClient(jobDiffOpts.ToName)
+		cobra.CheckErr(err)
+
+		cobra.CheckErr(jenkins.DiffJobs(from, to, jobDiffOpts))
+	},
+}
+
+var jobsGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Output the dependency graph of the jobs in a folder as DOT, Mermaid or JSON",
+	Long: `Output the dependency graph of the jobs in a folder as DOT, Mermaid or JSON.
+
+Edges go from a job to the jobs it triggers, found in the upstream and downstream projects of build triggers
+("trigger" edges) and in the build steps of pipeline scripts ("build" edges). Pipeline scripts loaded from SCM
+are not analyzed. Cycles and references to jobs that no longer exist are reported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.GraphJobs(jenkinsClient, jobGraphOpts))
+	},
+}
+
+func init() {
+	jobsListCmd.Flags().StringVar(&jobListOpts.Folder, "folder", "", "Folder to list (default is the root of the jenkins instance)")
+	jobsListCmd.Flags().BoolVarP(&jobListOpts.Recursive, "recursive", "r", false, "Include the contents of nested folders")
+	jobsListCmd.Flags().StringVar(&jobListOpts.Type, "type", "", "Only list items of this type: pipeline, freestyle, multibranch, folder, organization or matrix")
+	jobsListCmd.Flags().StringVar(&jobListOpts.Status, "status", "", "Only list jobs with this status, e.g. red, yellow, blue, disabled or notbuilt")
+	jobsListCmd.Flags().StringVar(&jobListOpts.Label, "label", "", "Only list jobs whose label expression refers to this label")
+	jobsListCmd.Flags().BoolVar(&jobListOpts.Tree, "tree", false, "Print the jobs as a tree")
+	jobsListCmd.Flags().StringVarP(&jobListOpts.Output, "output", "o", "text", "Output format: text or json")
+
+	jobsSearchCmd.Flags().StringVar(&jobSearchOpts.Folder, "folder", "", "Only search jobs in this folder, recursively (default is the whole jenkins instance)")
+	jobsSearchCmd.Flags().StringVar(&jobSearchOpts.XPath, "xpath", "", "XPath expression selecting the elements to search")
+	jobsSearchCmd.Flags().StringVar(&jobSearchOpts.Regex, "regex", "", "Regular expression to match against the text of the selected elements")
+	jobsSearchCmd.Flags().StringVarP(&jobSearchOpts.Output, "output", "o", "text", "Output format: text or json")
+
+	jobsTransformCmd.Flags().StringVar(&jobTransformOpts.Folder, "folder", "", "Only transform jobs in this folder, recursively (default is the whole jenkins instance)")
+	jobsTransformCmd.Flags().StringVar(&jobTransformOpts.XPath, "xpath", "", "XPath expression selecting the elements to edit")
+	jobsTransformCmd.Flags().StringVar(&jobTransformOpts.Regex, "regex", "", "Regular expression to replace in the text of the selected elements")
+	jobsTransformCmd.Flags().StringVar(&jobTransformOpts.Replace, "replace", "", "Replacement text or XML fragment (required)")
+	jobsTransformCmd.Flags().BoolVar(&jobTransformOpts.Plan, "plan", false, "Only show the changes")
+	jobsTransformCmd.Flags().BoolVarP(&jobTransformOpts.Yes, "yes", "y", false, "Apply the changes without asking for confirmation")
+	jobsTransformCmd.Flags().StringVar(&jobTransformOpts.RollbackFile, "rollback-file", "", "Path of the rollback file (default is tronci-rollback-<timestamp>.json)")
+	jobsTransformCmd.MarkFlagRequired("replace")
+
+	jobsRestoreCmd.Flags().BoolVarP(&jobRestoreYes, "yes", "y", false, "Restore without asking for confirmation")
+
+	jobsLintCmd.Flags().StringVar(&jobLintOpts.Folder, "folder", "", "Only lint jobs in this folder, recursively (default is the whole jenkins instance)")
+	jobsLintCmd.Flags().StringVar(&jobLintRules, "rules", "", "YAML file configuring the lint rules")
+	jobsLintCmd.Flags().StringVarP(&jobLintOpts.Output, "output", "o", "text", "Output format: text, json or sarif")
+
+	jobsExportCmd.Flags().StringVar(&jobExportOpts.Folder, "folder", "", "Folder to export, recursively (default is the whole jenkins instance)")
+	jobsExportCmd.Flags().StringVar(&jobExportOpts.Format, "format", "job-dsl", "Export format: job-dsl or xml-tree")
+	jobsExportCmd.Flags().StringVar(&jobExportOpts.Out, "out", "", "File of the Job DSL script (default is stdout), or directory of the XML tree")
+
+	jobsDiffCmd.Flags().StringVar(&jobDiffOpts.FromName, "from-context", "", "Context of the first jenkins instance (required)")
+	jobsDiffCmd.Flags().StringVar(&jobDiffOpts.ToName, "to-context", "", "Context of the second jenkins instance (required)")
+	jobsDiffCmd.Flags().StringVar(&jobDiffOpts.Folder, "folder", "", "Folder to compare, recursively (default is the whole jenkins instance)")
+	jobsDiffCmd.Flags().StringSliceVar(&jobDiffOpts.Ignore, "ignore", jenkins.DefaultJobDiffIgnore, "Elements, or attributes prefixed with @, to leave out of the comparison")
+	jobsDiffCmd.MarkFlagRequired("from-context")
+	jobsDiffCmd.MarkFlagRequired("to-context")
+
+	jobsGraphCmd.Flags().StringVar(&jobGraphOpts.Folder, "folder", "", "Only graph jobs in this folder, recursively (default is the whole jenkins instance)")
+	jobsGraphCmd.Flags().StringVarP(&jobGraphOpts.Format, "output", "o", "dot", "Output format: dot, mermaid or json")
+
+	jobsCmd.AddCommand(jobsListCmd)
+	jobsCmd.AddCommand(jobsSearchCmd)
+	jobsCmd.AddCommand(jobsTransformCmd)
+	jobsCmd.AddCommand(jobsRestoreCmd)
+	jobsCmd.AddCommand(jobsLintCmd)
+	jobsCmd.AddCommand(jobsExportCmd)
+	jobsCmd.AddCommand(jobsDiffCmd)
+	jobsCmd.AddCommand(jobsGraphCmd)
+}
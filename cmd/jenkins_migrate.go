@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var migrateOpts jenkins.MigrateOptions
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy jobs between jenkins instances defined as contexts in the config file",
+}
+
+var migrateJobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Copy the jobs and folders in a folder from one jenkins instance to another",
+	Long: `Copy the jobs and folders in a folder from one jenkins instance to another.
+
+Folders are created before their contents, and jobs after the jobs they are triggered by. Before copying an item,
+the plugins it uses and the credential IDs it refers to are looked up on the target instance: items with missing
+plugins or credentials are skipped, along with the contents of folders that are not on the target and could not
+be created. Items that already exist on the target are skipped unless --overwrite is given. With --dry-run, the
+credentials of folders that would be created or updated are taken from their config on the source instance. A
+report lists each item with what was done and why.
+
+The instances are contexts defined under jenkins.contexts in the config file (see "tronci jenkins jobs diff").`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		injectContextFlags(cmd, migrateOpts.FromName)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := newContextClient(migrateOpts.FromName)
+		cobra.CheckErr(err)
+		to, err := newContextClient(migrateOpts.ToName)
+		cobra.CheckErr(err)
+
+		cobra.CheckErr(jenkins.MigrateJobs(from, to, migrateOpts))
+	},
+}
+
+func init() {
+	migrateJobsCmd.Flags().StringVar(&migrateOpts.FromName, "from", "", "Context of the source jenkins instance (required)")
+	migrateJobsCmd.Flags().StringVar(&migrateOpts.ToName, "to", "", "Context of the target jenkins instance (required)")
+	migrateJobsCmd.Flags().StringVar(&migrateOpts.Folder, "folder", "", "Folder to copy, recursively (default is the whole jenkins instance)")
+	migrateJobsCmd.Flags().BoolVar(&migrateOpts.Overwrite, "overwrite", false, "Update items that exist on the target with a different config")
+	migrateJobsCmd.Flags().BoolVar(&migrateOpts.DryRun, "dry-run", false, "Only report what would be copied and skipped")
+	migrateJobsCmd.MarkFlagRequired("from")
+	migrateJobsCmd.MarkFlagRequired("to")
+
+	migrateCmd.AddCommand(migrateJobsCmd)
+}
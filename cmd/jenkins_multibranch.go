@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var multibranchOutput string
+
+var multibranchCmd = &cobra.Command{
+	Use:   "multibranch",
+	Short: "Index multibranch projects and list their branch and pull request jobs",
+}
+
+var multibranchScanCmd = &cobra.Command{
+	Use:   "scan <project>",
+	Short: "Trigger the branch indexing of a multibranch project and stream its log until it completes",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ScanMultibranch(jenkinsClient, args[0]))
+	},
+}
+
+var multibranchLogCmd = &cobra.Command{
+	Use:   "log <project>",
+	Short: "Output the log of the last branch indexing of a multibranch project",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.PrintIndexingLog(jenkinsClient, args[0]))
+	},
+}
+
+// Returns a command listing one kind of branch jobs of a multibranch project
+func newBranchListCmd(kind string, short string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   kind + " <project>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			jenkinsCreds := jenkins.Credentials{
+				Username: user,
+				APIToken: apiToken,
+			}
+			jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+			cobra.CheckErr(jenkins.ListBranchJobs(jenkinsClient, args[0], kind, multibranchOutput))
+		},
+	}
+	cmd.Flags().StringVarP(&multibranchOutput, "output", "o", "text", "Output format: text or json")
+	return cmd
+}
+
+func init() {
+	multibranchCmd.AddCommand(multibranchScanCmd)
+	multibranchCmd.AddCommand(multibranchLogCmd)
+	multibranchCmd.AddCommand(newBranchListCmd("branches", "List the branch jobs of a multibranch project with their last build"))
+	multibranchCmd.AddCommand(newBranchListCmd("prs", "List the pull request jobs of a multibranch project with their last build"))
+	multibranchCmd.AddCommand(newBranchListCmd("orphans", "List the branch jobs of a multibranch project whose branch no longer exists, pending deletion"))
+}
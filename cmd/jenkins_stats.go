@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var statsOpts jenkins.StatsOptions
+
+var statsCmd = &cobra.Command{
+	Use:   "stats <job|folder>",
+	Short: "Report queue time, execution time and success rate percentiles, and flag builds with unusual durations",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		bc, err := jenkins.NewBuildCache(cacheDir)
+		cobra.CheckErr(err)
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.GetBuildStats(jenkinsClient, bc, args[0], statsOpts))
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsOpts.Since, "since", "30d", "Only include builds scheduled after this age or date, e.g. \"30d\" or \"2026-09-01\"")
+	statsCmd.Flags().StringVarP(&statsOpts.Output, "output", "o", "text", "Output format: text, csv or json")
+	statsCmd.Flags().BoolVar(&statsOpts.Anomalies, "anomalies", false, "Output builds with anomalous durations instead of the per-job statistics")
+	statsCmd.Flags().Float64Var(&statsOpts.Threshold, "threshold", 3.5, "Number of median absolute deviations from the baseline at which a build is flagged")
+	statsCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory of the build cache (default is tronci in the user cache directory, e.g. $HOME/.cache/tronci on Linux)")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var syncOpts jenkins.SyncOptions
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror a directory tree of config.xml files onto jenkins",
+	Long: `Mirror a directory tree of config.xml files onto jenkins.
+
+Directories are folders, and each config.xml is the config of the job or folder at that path, e.g.
+jobs/team/app/config.xml is the config of the job team/app. Jobs and folders created or updated by sync have a
+marker added to their description; with --prune, marked items that are no longer in the directory are deleted.
+Items without the marker are never deleted.`,
+}
+
+var syncPlanCmd = &cobra.Command{
+	Use:   "plan <dir>",
+	Short: "Show the jobs and folders that sync apply would create, update and delete",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		syncOpts.Dir = args[0]
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.PlanSync(jenkinsClient, syncOpts))
+	},
+}
+
+var syncApplyCmd = &cobra.Command{
+	Use:   "apply <dir>",
+	Short: "Create, update and optionally prune jobs and folders so that jenkins matches a directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		syncOpts.Dir = args[0]
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.ApplySync(jenkinsClient, syncOpts))
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{syncPlanCmd, syncApplyCmd} {
+		c.Flags().StringVar(&syncOpts.Folder, "folder", "", "Folder to mirror the directory onto (default is the root of the jenkins instance)")
+		c.Flags().BoolVar(&syncOpts.Prune, "prune", false, "Delete managed jobs and folders that are not in the directory")
+		c.Flags().StringVar(&syncOpts.Marker, "marker", "", "Description marker of managed jobs (default \"[managed by tronci sync]\")")
+		c.Flags().BoolVar(&syncOpts.Diff, "diff", false, "Show the config diff of each job to update")
+	}
+	syncApplyCmd.Flags().BoolVarP(&syncOpts.Yes, "yes", "y", false, "Apply without asking for confirmation")
+
+	syncCmd.AddCommand(syncPlanCmd)
+	syncCmd.AddCommand(syncApplyCmd)
+}
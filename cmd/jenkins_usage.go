@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var usageOpts jenkins.UsageOptions
+
+var usageCmd = &cobra.Command{
+	Use:   "usage [folder]",
+	Short: "Report executor-hours consumed by builds, grouped by folder, label or node",
+	Long: `Report executor-hours consumed by builds, grouped by folder, label or node.
+
+Builds whose node is not known, e.g. pipelines whose stages did not report their agent, are reported under the
+"unknown" node. Executor-hours can be weighted by a cost per label by setting rates in the config file:
+
+  jenkins:
+    usage:
+      label-rates:
+        default: 0.10
+        gpu: 2.50`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		folder := ""
+		if len(args) > 0 {
+			folder = args[0]
+		}
+
+		cobra.CheckErr(viper.UnmarshalKey("jenkins.usage.label-rates", &usageOpts.LabelRates))
+
+		bc, err := jenkins.NewBuildCache(cacheDir)
+		cobra.CheckErr(err)
+
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.GetUsage(jenkinsClient, bc, folder, usageOpts))
+	},
+}
+
+func init() {
+	usageCmd.Flags().StringVar(&usageOpts.Since, "since", "30d", "Only include builds scheduled after this age or date, e.g. \"30d\" or \"2026-09-01\"")
+	usageCmd.Flags().StringVar(&usageOpts.GroupBy, "group-by", "folder-depth=1", "How to group builds: \"folder-depth=N\", \"label\" or \"node\"")
+	usageCmd.Flags().StringVarP(&usageOpts.Output, "output", "o", "csv", "Output format: csv or text")
+	usageCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory of the build cache (default is tronci in the user cache directory, e.g. $HOME/.cache/tronci on Linux)")
+}
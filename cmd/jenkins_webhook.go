@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.ibm.com/jmuro/tronci/pkg/jenkins"
+)
+
+var webhookOptions jenkins.WebhookOptions
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Send webhooks to the jenkins instance",
+}
+
+var webhookSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send a push event for a branch of a repository and report the jobs it scheduled",
+	Long: `Send a push event for a branch of a repository and report the jobs it scheduled.
+
+The event is crafted as the provider would send it and posted to /github-webhook/ for github, or to the endpoint
+of the Generic Webhook Trigger plugin for gitlab and generic (use --token for its job token). Use --endpoint to
+post elsewhere, e.g. /project/<job> for the GitLab plugin.
+
+The Generic Webhook Trigger plugin reports the jobs it triggered; otherwise the jobs queued or started within
+--wait are reported.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jenkinsCreds := jenkins.Credentials{
+			Username: user,
+			APIToken: apiToken,
+		}
+		jenkinsClient = jenkins.NewJenkinsClient(url, jenkinsCreds, enableDebug)
+		cobra.CheckErr(jenkins.SendWebhook(jenkinsClient, webhookOptions))
+	},
+}
+
+func init() {
+	webhookSendCmd.Flags().StringVar(&webhookOptions.Provider, "provider", "github", "Provider of the webhook: github, gitlab or generic")
+	webhookSendCmd.Flags().StringVar(&webhookOptions.Repo, "repo", "", "URL of the repository (required), e.g. https://github.com/owner/name")
+	webhookSendCmd.Flags().StringVar(&webhookOptions.Branch, "branch", "main", "Branch that was pushed")
+	webhookSendCmd.Flags().StringVar(&webhookOptions.SHA, "sha", "", "SHA of the commit that was pushed")
+	webhookSendCmd.Flags().StringVar(&webhookOptions.Secret, "secret", "", "Secret of the webhook: signs GitHub payloads, or is sent as the GitLab token")
+	webhookSendCmd.Flags().StringVar(&webhookOptions.Token, "token", "", "Token of the Generic Webhook Trigger plugin")
+	webhookSendCmd.Flags().StringVar(&webhookOptions.Endpoint, "endpoint", "", "Endpoint to post to (default is the endpoint of the provider)")
+	webhookSendCmd.Flags().StringVar(&webhookOptions.Folder, "folder", "", "Only report builds of jobs in this folder, recursively (default is the whole jenkins instance)")
+	webhookSendCmd.Flags().DurationVar(&webhookOptions.Wait, "wait", 5*time.Second, "Time to wait for jobs to be scheduled")
+	webhookSendCmd.MarkFlagRequired("repo")
+
+	webhookCmd.AddCommand(webhookSendCmd)
+}
@@ -10,4 +10,5 @@ require (
 	github.com/spf13/viper v1.8.1
 	golang.org/x/mod v0.4.2
 	golang.org/x/oauth2 v0.0.0-20210819190943-2bc19b11175f
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
@@ -0,0 +1,241 @@
+package jenkins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muroj/gojenkins"
+)
+
+// BuildMetadata holds the editable attributes of a build. Nil fields are left unchanged.
+type BuildMetadata struct {
+	Description *string
+	DisplayName *string
+	KeepForever *bool
+}
+
+// SetBuildMetadata updates the description, display name and keep-forever flag of a build
+func SetBuildMetadata(c *APIClient, jobURL string, id int64, meta BuildMetadata) error {
+	job, build, err := getJobBuild(c, jobURL, id)
+	if err != nil {
+		return err
+	}
+	base := buildBase(job, id)
+
+	if meta.DisplayName != nil {
+		// configSubmit replaces both fields, so carry over the current description unless a new one is given
+		description, _ := build.Raw.Description.(string)
+		if meta.Description != nil {
+			description = *meta.Description
+		}
+
+		form := url.Values{}
+		form.Set("json", fmt.Sprintf(`{"displayName": %s, "description": %s}`, jsonString(*meta.DisplayName), jsonString(description)))
+		if err := postForm(c, base+"/configSubmit", form); err != nil {
+			return fmt.Errorf("failed to update display name of %s #%d: %s", job.GetDetails().FullName, id, err)
+		}
+		log.Printf("Set display name of %s #%d to \"%s\"", job.GetDetails().FullName, id, *meta.DisplayName)
+	} else if meta.Description != nil {
+		form := url.Values{}
+		form.Set("description", *meta.Description)
+		if err := postForm(c, base+"/submitDescription", form); err != nil {
+			return fmt.Errorf("failed to update description of %s #%d: %s", job.GetDetails().FullName, id, err)
+		}
+	}
+
+	if meta.Description != nil {
+		log.Printf("Set description of %s #%d", job.GetDetails().FullName, id)
+	}
+
+	if meta.KeepForever != nil {
+		// toggleLogKeep flips the current value, so only call it if the build is not already in the requested state
+		if build.Raw.KeepLog != *meta.KeepForever {
+			if err := postForm(c, base+"/toggleLogKeep", nil); err != nil {
+				return fmt.Errorf("failed to update keep-forever flag of %s #%d: %s", job.GetDetails().FullName, id, err)
+			}
+		}
+		log.Printf("Set keep-forever of %s #%d to %t", job.GetDetails().FullName, id, *meta.KeepForever)
+	}
+
+	return nil
+}
+
+// Returns the job at jobURL and its build with the specified number
+func getJobBuild(c *APIClient, jobURL string, id int64) (*gojenkins.Job, *gojenkins.Build, error) {
+	jobName, parents, _ := parseJobURL(jobURL)
+	job, err := c.Client.GetJob(c.Context, jobName, parents...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to retreive job at URL \"%s\": %s", jobURL, err)
+	}
+
+	build, err := job.GetBuild(c.Context, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve build %d: %s", id, err)
+	}
+
+	return job, build, nil
+}
+
+// Returns the API path of a build, e.g. "/job/a/job/b/42"
+func buildBase(job *gojenkins.Job, id int64) string {
+	return fmt.Sprintf("%s/%d", job.Base, id)
+}
+
+/*
+	POSTs form data to a Jenkins endpoint. Jenkins answers most form submissions with a redirect, which is
+	followed, so any status other than 200 indicates a failure.
+*/
+func postForm(c *APIClient, endpoint string, form url.Values) error {
+	var payload *bytes.Buffer
+	if form != nil {
+		payload = bytes.NewBufferString(form.Encode())
+	} else {
+		payload = bytes.NewBufferString("")
+	}
+
+	resp, err := c.Client.Requester.Post(c.Context, endpoint, payload, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from %s: %s", endpoint, resp.Status)
+	}
+
+	return nil
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+type PruneOptions struct {
+	KeepLast    int
+	KeepDays    int
+	KeepResults []string
+	DryRun      bool
+	// Delete without asking for confirmation
+	Yes         bool
+	Concurrency int
+}
+
+type buildSummary struct {
+	Number    int64  `json:"number"`
+	Timestamp int64  `json:"timestamp"`
+	Result    string `json:"result"`
+	KeepLog   bool   `json:"keepLog"`
+	Building  bool   `json:"building"`
+}
+
+type pruneCandidate struct {
+	Job   JobRef
+	Build buildSummary
+}
+
+/*
+	Deletes the builds of every job at or below jobURL that are not retained by the policy in opts. Builds marked
+	keep-forever and builds still running are always retained. The deletion plan is printed and confirmed before any build is deleted.
+*/
+func PruneBuilds(c *APIClient, jobURL string, opts PruneOptions) error {
+	jobs, err := walkJobs(c, jobFullName(jobURL))
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -opts.KeepDays)
+	keepResults := make(map[string]bool)
+	for _, r := range opts.KeepResults {
+		keepResults[strings.ToUpper(r)] = true
+	}
+
+	var candidates []pruneCandidate
+	for _, j := range jobs {
+		var resp struct {
+			Builds []buildSummary `json:"allBuilds"`
+		}
+		query := map[string]string{"tree": "allBuilds[number,timestamp,result,keepLog,building]"}
+		if _, err := c.Client.Requester.GetJSON(c.Context, jobBase(j.FullName), &resp, query); err != nil {
+			return fmt.Errorf("unable to list builds of \"%s\": %s", j.FullName, err)
+		}
+
+		sort.Slice(resp.Builds, func(i, k int) bool { return resp.Builds[i].Number > resp.Builds[k].Number })
+		for i, b := range resp.Builds {
+			switch {
+			case i < opts.KeepLast,
+				b.KeepLog,
+				b.Building,
+				keepResults[b.Result],
+				opts.KeepDays > 0 && !time.Unix(0, b.Timestamp*int64(time.Millisecond)).Before(cutoff):
+				continue
+			}
+			candidates = append(candidates, pruneCandidate{Job: j, Build: b})
+		}
+	}
+
+	for _, p := range candidates {
+		fmt.Printf("delete %s #%d (%s, %s)\n", p.Job.FullName, p.Build.Number, time.Unix(0, p.Build.Timestamp*int64(time.Millisecond)).Format("2006-01-02"), p.Build.Result)
+	}
+	fmt.Printf("Plan: %d builds to delete from %d jobs\n", len(candidates), len(jobs))
+
+	if opts.DryRun || len(candidates) == 0 {
+		return nil
+	}
+
+	pruned := make(map[string]bool)
+	for _, p := range candidates {
+		pruned[p.Job.FullName] = true
+	}
+	if !opts.Yes && !confirm(fmt.Sprintf("Delete %d builds from %d jobs?", len(candidates), len(pruned))) {
+		return fmt.Errorf("aborted")
+	}
+
+	return deleteBuilds(c, candidates, opts.Concurrency)
+}
+
+// Deletes builds using at most concurrency simultaneous requests to avoid overloading the Jenkins master
+func deleteBuilds(c *APIClient, candidates []pruneCandidate, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	work := make(chan pruneCandidate)
+	var failed int32
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				endpoint := fmt.Sprintf("%s/%d/doDelete", jobBase(p.Job.FullName), p.Build.Number)
+				if err := postForm(c, endpoint, nil); err != nil {
+					log.Printf("failed to delete %s #%d: %s", p.Job.FullName, p.Build.Number, err)
+					atomic.AddInt32(&failed, 1)
+				} else if c.DebugMode {
+					log.Printf("deleted %s #%d", p.Job.FullName, p.Build.Number)
+				}
+			}
+		}()
+	}
+
+	for _, p := range candidates {
+		work <- p
+	}
+	close(work)
+	wg.Wait()
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d builds", failed, len(candidates))
+	}
+	log.Printf("Deleted %d builds", len(candidates))
+
+	return nil
+}
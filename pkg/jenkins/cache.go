@@ -0,0 +1,555 @@
+package jenkins
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muroj/gojenkins"
+)
+
+const cacheSegmentName = "builds.jsonl"
+
+// Name of the file recording the builds of a job removed by Prune, next to the segment of the job
+const cachePruneMarkName = "pruned.json"
+
+// BuildCache stores completed builds on disk so that analysis commands do not need to re-fetch them.
+// Records are kept as JSON lines in one segment per job: <Dir>/<instance>/<job full name>/builds.jsonl
+type BuildCache struct {
+	Dir string
+}
+
+// CachedBuild is a single record in the build cache. Completed builds are immutable, so a record never
+// needs to be refreshed once written.
+type CachedBuild struct {
+	Instance string
+	Job      string
+	Result   string
+	Build    BuildInfo
+	Stages   []StageInfo  `json:",omitempty"`
+	Tests    *TestSummary `json:",omitempty"`
+	CachedAt time.Time
+}
+
+type StageInfo struct {
+	Name          string
+	Status        string
+	StartTimeUnix int64
+	DurationMs    int64
+}
+
+type TestSummary struct {
+	Passed      int64
+	Failed      int64
+	Skipped     int64
+	DurationSec float64
+	FailedCases []string `json:",omitempty"`
+}
+
+/*
+	pruneMark records the most recent build of a job removed by Prune, so that sync does not fetch the pruned
+	builds again. Build numbers increase with the time builds are scheduled, so every build up to Through is
+	older than the cutoff.
+*/
+type pruneMark struct {
+	Through int64
+	Cutoff  time.Time
+}
+
+type CacheStats struct {
+	Instance  string
+	Jobs      int
+	Builds    int
+	SizeBytes int64
+	Oldest    time.Time
+	Newest    time.Time
+}
+
+// NewBuildCache returns a cache rooted at dir, or at "<user cache dir>/tronci" if dir is empty
+func NewBuildCache(dir string) (*BuildCache, error) {
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine cache directory: %s", err)
+		}
+		dir = filepath.Join(userCacheDir, "tronci")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create cache directory \"%s\": %s", dir, err)
+	}
+
+	return &BuildCache{Dir: dir}, nil
+}
+
+// Load returns the cached builds of a job keyed by build number
+func (bc *BuildCache) Load(instance string, job string) (map[int64]CachedBuild, error) {
+	builds := make(map[int64]CachedBuild)
+
+	records, err := readSegment(bc.segmentPath(instance, job))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range records {
+		builds[r.Build.BuildID] = r
+	}
+
+	return builds, nil
+}
+
+// Append adds records to the segment of a job
+func (bc *BuildCache) Append(instance string, job string, records []CachedBuild) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	segment := bc.segmentPath(instance, job)
+	if err := os.MkdirAll(filepath.Dir(segment), 0755); err != nil {
+		return fmt.Errorf("unable to create cache directory for \"%s\": %s", job, err)
+	}
+
+	f, err := os.OpenFile(segment, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open cache segment \"%s\": %s", segment, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("failed to write cache segment \"%s\": %s", segment, err)
+		}
+	}
+
+	return nil
+}
+
+// Builds returns every cached build of the specified instance whose job is at or below prefix
+func (bc *BuildCache) Builds(instance string, prefix string) ([]CachedBuild, error) {
+	var builds []CachedBuild
+
+	err := bc.walkSegments(instance, func(segment string) error {
+		records, err := readSegment(segment)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if prefix == "" || r.Job == prefix || strings.HasPrefix(r.Job, prefix+"/") {
+				builds = append(builds, r)
+			}
+		}
+		return nil
+	})
+
+	return builds, err
+}
+
+// Prune removes records of builds scheduled before the cutoff. If instance is empty, all instances are pruned.
+func (bc *BuildCache) Prune(instance string, cutoff time.Time) (int, error) {
+	removed := 0
+
+	err := bc.walkSegments(instance, func(segment string) error {
+		records, err := readSegment(segment)
+		if err != nil {
+			return err
+		}
+
+		var kept []CachedBuild
+		var through int64
+		for _, r := range records {
+			if r.Build.ScheduledTimestamp.Before(cutoff) {
+				removed++
+				if r.Build.BuildID > through {
+					through = r.Build.BuildID
+				}
+			} else {
+				kept = append(kept, r)
+			}
+		}
+
+		if len(kept) == len(records) {
+			return nil
+		}
+		if err := updatePruneMark(filepath.Join(filepath.Dir(segment), cachePruneMarkName), through, cutoff); err != nil {
+			return err
+		}
+		if len(kept) == 0 {
+			return os.Remove(segment)
+		}
+		return writeSegment(segment, kept)
+	})
+
+	return removed, err
+}
+
+// Stats summarizes the contents of the cache per instance
+func (bc *BuildCache) Stats() ([]CacheStats, error) {
+	statsByInstance := make(map[string]*CacheStats)
+
+	err := bc.walkSegments("", func(segment string) error {
+		rel, _ := filepath.Rel(bc.Dir, segment)
+		instance := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+
+		s, ok := statsByInstance[instance]
+		if !ok {
+			s = &CacheStats{Instance: instance}
+			statsByInstance[instance] = s
+		}
+
+		fi, err := os.Stat(segment)
+		if err != nil {
+			return err
+		}
+		records, err := readSegment(segment)
+		if err != nil {
+			return err
+		}
+
+		s.Jobs++
+		s.Builds += len(records)
+		s.SizeBytes += fi.Size()
+		for _, r := range records {
+			ts := r.Build.ScheduledTimestamp
+			if s.Oldest.IsZero() || ts.Before(s.Oldest) {
+				s.Oldest = ts
+			}
+			if ts.After(s.Newest) {
+				s.Newest = ts
+			}
+		}
+		return nil
+	})
+
+	var stats []CacheStats
+	for _, s := range statsByInstance {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Instance < stats[j].Instance })
+
+	return stats, err
+}
+
+// PrunedThrough returns the most recent build of a job removed by Prune, or 0 if none was
+func (bc *BuildCache) PrunedThrough(instance string, job string) (int64, error) {
+	mark, err := readPruneMark(filepath.Join(filepath.Dir(bc.segmentPath(instance, job)), cachePruneMarkName))
+	return mark.Through, err
+}
+
+func (bc *BuildCache) segmentPath(instance string, job string) string {
+	return filepath.Join(bc.Dir, instance, filepath.FromSlash(job), cacheSegmentName)
+}
+
+func (bc *BuildCache) walkSegments(instance string, fn func(segment string) error) error {
+	root := filepath.Join(bc.Dir, instance)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || fi.Name() != cacheSegmentName {
+			return nil
+		}
+		return fn(p)
+	})
+}
+
+func readSegment(segment string) ([]CachedBuild, error) {
+	f, err := os.Open(segment)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open cache segment \"%s\": %s", segment, err)
+	}
+	defer f.Close()
+
+	var records []CachedBuild
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r CachedBuild
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("corrupt record in cache segment \"%s\": %s", segment, err)
+		}
+		records = append(records, r)
+	}
+
+	return records, scanner.Err()
+}
+
+func writeSegment(segment string, records []CachedBuild) error {
+	tmp := segment + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("unable to rewrite cache segment \"%s\": %s", segment, err)
+	}
+
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write cache segment \"%s\": %s", segment, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, segment)
+}
+
+func readPruneMark(file string) (pruneMark, error) {
+	var mark pruneMark
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return mark, nil
+	} else if err != nil {
+		return mark, fmt.Errorf("unable to read prune mark \"%s\": %s", file, err)
+	}
+	if err := json.Unmarshal(data, &mark); err != nil {
+		return mark, fmt.Errorf("corrupt prune mark \"%s\": %s", file, err)
+	}
+	return mark, nil
+}
+
+// Raises the prune mark in file to the build through, keeping the most recent cutoff
+func updatePruneMark(file string, through int64, cutoff time.Time) error {
+	mark, err := readPruneMark(file)
+	if err != nil {
+		return err
+	}
+	if through > mark.Through {
+		mark.Through = through
+	}
+	if cutoff.After(mark.Cutoff) {
+		mark.Cutoff = cutoff
+	}
+
+	data, _ := json.Marshal(mark)
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("unable to write prune mark \"%s\": %s", file, err)
+	}
+	return nil
+}
+
+/*
+	Returns the directory name used to key a Jenkins instance in the cache, e.g. "https://ci.example.com/jenkins/"
+	becomes "ci.example.com_jenkins".
+*/
+func cacheInstanceKey(jenkinsURL string) string {
+	u, err := url.Parse(jenkinsURL)
+	if err != nil || u.Host == "" {
+		return strings.NewReplacer("/", "_", ":", "_").Replace(jenkinsURL)
+	}
+
+	key := strings.ReplaceAll(u.Host, ":", "_")
+	if p := strings.Trim(u.Path, "/"); p != "" {
+		key += "_" + strings.ReplaceAll(p, "/", "_")
+	}
+
+	return key
+}
+
+/*
+	SyncBuildCache fetches every completed build at or below jobURL that is not yet cached. Builds removed from the
+	cache by PruneBuildCache are not fetched again.
+*/
+func SyncBuildCache(c *APIClient, bc *BuildCache, jobURL string) error {
+	instance := cacheInstanceKey(c.Client.Server)
+
+	jobs, err := walkJobs(c, jobFullName(jobURL))
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, j := range jobs {
+		added, err := syncJob(c, bc, instance, j.FullName)
+		if err != nil {
+			return err
+		}
+		total += added
+	}
+
+	log.Printf("Cached %d new builds from %d jobs", total, len(jobs))
+
+	return nil
+}
+
+func syncJob(c *APIClient, bc *BuildCache, instance string, fullName string) (int, error) {
+	cached, err := bc.Load(instance, fullName)
+	if err != nil {
+		return 0, err
+	}
+	prunedThrough, err := bc.PrunedThrough(instance, fullName)
+	if err != nil {
+		return 0, err
+	}
+
+	name, parents, _ := parseJobURL(fullName)
+	job, err := c.Client.GetJob(c.Context, name, parents...)
+	if err != nil {
+		return 0, fmt.Errorf("unable to retrieve job \"%s\": %s", fullName, err)
+	}
+
+	ids, err := job.GetAllBuildIds(c.Context)
+	if err != nil {
+		return 0, fmt.Errorf("unable to list builds of \"%s\": %s", fullName, err)
+	}
+
+	var records []CachedBuild
+	for _, id := range ids {
+		if _, ok := cached[id.Number]; ok || id.Number <= prunedThrough {
+			continue
+		}
+
+		build, err := job.GetBuild(c.Context, id.Number)
+		if err != nil {
+			log.Printf("skipping %s #%d: %s", fullName, id.Number, err)
+			continue
+		}
+		if build.Raw.Building {
+			continue
+		}
+
+		records = append(records, newCachedBuild(c, instance, job, build))
+	}
+
+	if err := bc.Append(instance, fullName, records); err != nil {
+		return 0, err
+	}
+
+	if c.DebugMode || len(records) > 0 {
+		log.Printf("%s: %d new builds", fullName, len(records))
+	}
+
+	return len(records), nil
+}
+
+func newCachedBuild(c *APIClient, instance string, job *gojenkins.Job, build *gojenkins.Build) CachedBuild {
+	record := CachedBuild{
+		Instance: instance,
+		Job:      job.GetDetails().FullName,
+		Result:   build.GetResult(),
+		Build:    newBuildInfo(job, build),
+		CachedAt: time.Now(),
+	}
+
+	if job.IsPipelineJob() {
+		// pipelines are not built on a single node, so builtOn is empty: use the agent the stages mostly ran on
+		var run struct {
+			Stages []struct {
+				Name      string `json:"name"`
+				Status    string `json:"status"`
+				StartTime int64  `json:"startTimeMillis"`
+				Duration  int64  `json:"durationMillis"`
+				ExecNode  string `json:"execNode"`
+			} `json:"stages"`
+		}
+		// gojenkins' GetPipelineRun appends "api/json" to the wfapi endpoint, so request it directly
+		if _, err := c.Client.Requester.Get(c.Context, job.Base+"/"+build.Raw.ID+"/wfapi/describe", &run, nil); err == nil {
+			nodeTime := make(map[string]int64)
+			for _, s := range run.Stages {
+				record.Stages = append(record.Stages, StageInfo{
+					Name:          s.Name,
+					Status:        s.Status,
+					StartTimeUnix: s.StartTime / 1000,
+					DurationMs:    s.Duration,
+				})
+				if s.ExecNode != "" {
+					nodeTime[s.ExecNode] += s.Duration
+				}
+			}
+			best := int64(-1)
+			for node, ms := range nodeTime {
+				if ms > best || (ms == best && node < record.Build.AgentHostMachine) {
+					record.Build.AgentHostMachine, best = node, ms
+				}
+			}
+		}
+	} else {
+		// builtOn is empty for builds on the built-in node
+		record.Build.AgentHostMachine = build.Raw.BuiltOn
+		if record.Build.AgentHostMachine == "" {
+			record.Build.AgentHostMachine = builtInNodeName
+		}
+	}
+
+	if report, err := build.GetResultSet(c.Context); err == nil && report.PassCount+report.FailCount+report.SkipCount > 0 {
+		tests := TestSummary{
+			Passed:      report.PassCount,
+			Failed:      report.FailCount,
+			Skipped:     report.SkipCount,
+			DurationSec: report.Duration,
+		}
+		for _, suite := range report.Suites {
+			for _, tc := range suite.Cases {
+				if tc.Status == "FAILED" || tc.Status == "REGRESSION" {
+					tests.FailedCases = append(tests.FailedCases, tc.ClassName+"."+tc.Name)
+				}
+			}
+		}
+		record.Tests = &tests
+	}
+
+	return record
+}
+
+func (s *CacheStats) PrintCacheStats() {
+	fmt.Printf("Instance: %s\n", s.Instance)
+	fmt.Printf("  Jobs: %d\n", s.Jobs)
+	fmt.Printf("  Builds: %d\n", s.Builds)
+	fmt.Printf("  Size (KB): %d\n", s.SizeBytes/1024)
+	if s.Builds > 0 {
+		fmt.Printf("  Oldest build: %s\n", s.Oldest.String())
+		fmt.Printf("  Newest build: %s\n", s.Newest.String())
+	}
+}
+
+// PrintBuildCacheStats outputs a summary of the cache contents
+func PrintBuildCacheStats(bc *BuildCache) error {
+	stats, err := bc.Stats()
+	if err != nil {
+		return fmt.Errorf("unable to read build cache: %s", err)
+	}
+
+	fmt.Printf("Cache directory: %s\n", bc.Dir)
+	for _, s := range stats {
+		s.PrintCacheStats()
+	}
+
+	return nil
+}
+
+// PruneBuildCache removes cached builds scheduled before the time indicated by olderThan, e.g. "90d" or "2026-01-01"
+func PruneBuildCache(bc *BuildCache, jenkinsURL string, olderThan string) error {
+	cutoff, err := parseSince(olderThan, time.Now())
+	if err != nil {
+		return err
+	}
+
+	instance := ""
+	if jenkinsURL != "" {
+		instance = cacheInstanceKey(jenkinsURL)
+	}
+
+	removed, err := bc.Prune(instance, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to prune build cache: %s", err)
+	}
+
+	log.Printf("Removed %d builds scheduled before %s", removed, cutoff.Format(time.RFC3339))
+
+	return nil
+}
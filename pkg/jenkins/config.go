@@ -0,0 +1,168 @@
+package jenkins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// GetJobConfig outputs the config.xml of a job
+func GetJobConfig(c *APIClient, jobURL string) error {
+	config, err := getJobConfig(c, jobFullName(jobURL))
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(config)
+
+	return nil
+}
+
+/*
+	Shows the difference between the config.xml in file ("-" for stdin) and the current config of the job,
+	then, if apply is set, posts it. The job is created if it does not exist.
+*/
+func ApplyJobConfig(c *APIClient, jobURL string, file string, apply bool) error {
+	config, err := readConfigFile(file)
+	if err != nil {
+		return err
+	}
+
+	fullName := jobFullName(jobURL)
+	changed, exists, err := previewJobConfig(c, fullName, config, file)
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		log.Printf("%s is up to date", fullName)
+		return nil
+	}
+	if !apply {
+		log.Printf("Run again with --yes to apply these changes")
+		return nil
+	}
+
+	if exists {
+		err = updateJobConfig(c, fullName, config)
+	} else {
+		err = createJob(c, fullName, config)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Applied config to %s", fullName)
+
+	return nil
+}
+
+/*
+	Prints the normalized diff between the current config of a job and config. Returns whether the config
+	differs and whether the job exists.
+*/
+func previewJobConfig(c *APIClient, fullName string, config string, source string) (bool, bool, error) {
+	desired, err := normalizeXML(config)
+	if err != nil {
+		return false, false, fmt.Errorf("%s: %s", source, err)
+	}
+
+	exists, err := jobExists(c, fullName)
+	if err != nil {
+		return false, false, err
+	}
+
+	current := ""
+	if exists {
+		currentConfig, err := getJobConfig(c, fullName)
+		if err != nil {
+			return false, false, err
+		}
+		if current, err = normalizeXML(currentConfig); err != nil {
+			return false, false, fmt.Errorf("current config of \"%s\": %s", fullName, err)
+		}
+	} else {
+		fmt.Printf("%s does not exist and will be created\n", fullName)
+	}
+
+	diff := unifiedDiff(current, desired, fullName+" (current)", source, 3)
+	fmt.Print(diff)
+
+	return diff != "", exists, nil
+}
+
+func jobExists(c *APIClient, fullName string) (bool, error) {
+	var job JobRef
+	resp, err := c.Client.Requester.GetJSON(c.Context, jobBase(fullName), &job, map[string]string{"tree": "name"})
+	if err != nil {
+		return false, fmt.Errorf("unable to retrieve \"%s\": %s", fullName, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, fmt.Errorf("unable to retrieve \"%s\": %s", fullName, resp.Status)
+}
+
+func updateJobConfig(c *APIClient, fullName string, config string) error {
+	resp, err := c.Client.Requester.PostXML(c.Context, jobBase(fullName)+"/config.xml", config, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update config of \"%s\": %s", fullName, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update config of \"%s\": %s", fullName, resp.Status)
+	}
+
+	return nil
+}
+
+// Creates a job, or a folder if config describes one, in the folder indicated by its full name
+func createJob(c *APIClient, fullName string, config string) error {
+	parent, name := splitFullName(fullName)
+
+	endpoint := "/createItem"
+	if parent != "" {
+		endpoint = jobBase(parent) + "/createItem"
+	}
+
+	resp, err := c.Client.Requester.PostXML(c.Context, endpoint, config, nil, map[string]string{"name": name})
+	if err != nil {
+		return fmt.Errorf("failed to create \"%s\": %s", fullName, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to create \"%s\": %s", fullName, resp.Status)
+	}
+
+	return nil
+}
+
+// Splits a full name such as "a/b/c" into the parent folder "a/b" and the item name "c"
+func splitFullName(fullName string) (string, string) {
+	i := strings.LastIndex(fullName, "/")
+	if i < 0 {
+		return "", fullName
+	}
+	return fullName[:i], fullName[i+1:]
+}
+
+func readConfigFile(file string) (string, error) {
+	var content []byte
+	var err error
+	if file == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read \"%s\": %s", file, err)
+	}
+
+	return string(content), nil
+}
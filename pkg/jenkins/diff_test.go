@@ -0,0 +1,80 @@
+package jenkins
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []string
+	}{
+		{"identical", []string{"a", "b"}, []string{"a", "b"}, []string{" a", " b"}},
+		{"replace", []string{"a", "b", "c"}, []string{"a", "x", "c"}, []string{" a", "-b", "+x", " c"}},
+		{"insert", []string{"a", "c"}, []string{"a", "b", "c"}, []string{" a", "+b", " c"}},
+		{"delete", []string{"a", "b", "c"}, []string{"a", "c"}, []string{" a", "-b", " c"}},
+		{"from empty", nil, []string{"a"}, []string{"+a"}},
+		{"to empty", []string{"a", "b"}, nil, []string{"-a", "-b"}},
+		{"moved line", []string{"a", "b", "c", "d"}, []string{"b", "c", "a", "d"}, []string{"-a", " b", " c", "+a", " d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, op := range diffLines(tt.a, tt.b) {
+				got = append(got, string(op.Kind)+op.Line)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(ls ...string) string { return strings.Join(ls, "\n") + "\n" }
+
+	tests := []struct {
+		name     string
+		from, to string
+		context  int
+		want     string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", 3, ""},
+		{
+			"single hunk", lines("a", "b", "c"), lines("a", "x", "c"), 1,
+			lines("--- from", "+++ to", "@@ -1,3 +1,3 @@", " a", "-b", "+x", " c"),
+		},
+		{
+			"from empty", "", lines("a"), 3,
+			lines("--- from", "+++ to", "@@ -0,0 +1,1 @@", "+a"),
+		},
+		{
+			"separate hunks",
+			lines("l1", "l2", "l3", "l4", "l5", "l6", "l7", "l8", "l9", "l10"),
+			lines("l1", "X", "l3", "l4", "l5", "l6", "l7", "l8", "Y", "l10"),
+			1,
+			lines("--- from", "+++ to",
+				"@@ -1,3 +1,3 @@", " l1", "-l2", "+X", " l3",
+				"@@ -8,3 +8,3 @@", " l8", "-l9", "+Y", " l10"),
+		},
+		{
+			"merged hunks",
+			lines("l1", "l2", "l3", "l4", "l5"),
+			lines("l1", "X", "l3", "Y", "l5"),
+			1,
+			lines("--- from", "+++ to", "@@ -1,5 +1,5 @@", " l1", "-l2", "+X", " l3", "-l4", "+Y", " l5"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff(tt.from, tt.to, "from", "to", tt.context); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
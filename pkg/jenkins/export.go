@@ -0,0 +1,344 @@
+package jenkins
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ExportOptions struct {
+	Folder string
+	// "job-dsl" or "xml-tree"
+	Format string
+	// File of the Job DSL script (default is stdout), or directory of the XML tree
+	Out string
+}
+
+// Top-level config elements that Job DSL output handles natively, by root element
+var jobDSLHandled = map[string][]string{
+	"com.cloudbees.hudson.plugins.folder.Folder":                            {"description", "displayName"},
+	"flow-definition":                                                       {"description", "displayName", "disabled", "definition"},
+	"org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject": {"description", "displayName", "sources", "factory"},
+	"project": {"description", "displayName", "disabled"},
+}
+
+// Values of config elements that are the defaults of the Job DSL methods, and can be left out
+var jobDSLDefaults = map[string]string{
+	"configVersion":                     "2",
+	"doGenerateSubmoduleConfigurations": "false",
+}
+
+/*
+	Exports the jobs and folders in a folder, recursively, either as a Job DSL script or as a directory tree of
+	config.xml files that "sync" can apply. Job DSL output uses the native methods of folders, pipelines,
+	multibranch pipelines and freestyle jobs where possible, and configure blocks for the rest of their config.
+	The contents of multibranch projects and organization folders are not exported, since they are generated.
+*/
+func ExportJobs(c *APIClient, opts ExportOptions) error {
+	scope := jobFullName(opts.Folder)
+	items, err := walkTree(c, scope, true)
+	if err != nil {
+		return err
+	}
+
+	var exported []JobRef
+	for _, item := range items {
+		if !isComputedChild(item.FullName, items) {
+			exported = append(exported, item)
+		}
+	}
+
+	configs, err := getItemConfigs(c, exported)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case "xml-tree":
+		if opts.Out == "" {
+			return fmt.Errorf("an output directory is required for the xml-tree format")
+		}
+		return writeXMLTree(exported, configs, scope, opts.Out)
+	case "job-dsl":
+		out := io.Writer(os.Stdout)
+		if opts.Out != "" {
+			f, err := os.Create(opts.Out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		return writeJobDSL(out, exported, configs)
+	}
+
+	return fmt.Errorf("invalid format \"%s\": expected job-dsl or xml-tree", opts.Format)
+}
+
+// Writes each config to dir/<name relative to scope>/config.xml
+func writeXMLTree(items []JobRef, configs map[string]string, scope string, dir string) error {
+	for _, item := range items {
+		rel := strings.TrimPrefix(item.FullName, scope+"/")
+		if scope == "" {
+			rel = item.FullName
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(path, "config.xml"), []byte(configs[item.FullName]), 0644); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Exported %d jobs and folders to %s", len(items), dir)
+
+	return nil
+}
+
+func writeJobDSL(w io.Writer, items []JobRef, configs map[string]string) error {
+	fmt.Fprintln(w, "// Generated by tronci jenkins jobs export")
+	skipped := 0
+	for _, item := range items {
+		root, err := parseXML(configs[item.FullName])
+		if err != nil {
+			return fmt.Errorf("config of \"%s\": %s", item.FullName, err)
+		}
+
+		var method string
+		switch root.Name {
+		case "com.cloudbees.hudson.plugins.folder.Folder":
+			method = "folder"
+		case "flow-definition":
+			method = "pipelineJob"
+		case "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject":
+			method = "multibranchPipelineJob"
+		case "project":
+			method = "job"
+		default:
+			fmt.Fprintf(w, "\n// %s: %s is not supported by this export\n", item.FullName, root.Name)
+			log.Printf("Skipping %s: %s is not supported", item.FullName, root.Name)
+			skipped++
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s(%s) {\n", method, groovyString(item.FullName))
+		unhandled := writeJobDSLBody(w, root)
+		writeJobDSLConfigure(w, unhandled)
+		fmt.Fprintln(w, "}")
+	}
+
+	if skipped > 0 {
+		return fmt.Errorf("%d of %d items could not be exported", skipped, len(items))
+	}
+
+	return nil
+}
+
+// Writes the natively supported parts of a config and returns the top-level elements left for a configure block
+func writeJobDSLBody(w io.Writer, root *xmlNode) []*xmlNode {
+	var unhandled []*xmlNode
+	handled := make(map[string]bool)
+	for _, name := range jobDSLHandled[root.Name] {
+		handled[name] = true
+	}
+
+	for _, n := range root.Children {
+		text := strings.TrimSpace(n.Text)
+		if !handled[n.Name] {
+			// empty elements such as <actions/> are defaults
+			if len(n.Children) > 0 || len(n.Attrs) > 0 || text != "" {
+				unhandled = append(unhandled, n)
+			}
+			continue
+		}
+
+		switch n.Name {
+		case "description", "displayName":
+			if text != "" {
+				fmt.Fprintf(w, "    %s(%s)\n", n.Name, groovyString(n.Text))
+			}
+		case "disabled":
+			if text == "true" {
+				fmt.Fprintln(w, "    disabled()")
+			}
+		case "definition":
+			if !writePipelineDefinition(w, n) {
+				unhandled = append(unhandled, n)
+			}
+		case "sources":
+			if !writeBranchSources(w, n) {
+				unhandled = append(unhandled, n)
+			}
+		case "factory":
+			if n.attr("class") != "org.jenkinsci.plugins.workflow.multibranch.WorkflowBranchProjectFactory" {
+				unhandled = append(unhandled, n)
+				continue
+			}
+			fmt.Fprintln(w, "    factory {")
+			fmt.Fprintln(w, "        workflowBranchProjectFactory {")
+			fmt.Fprintf(w, "            scriptPath(%s)\n", groovyString(n.childText("scriptPath", "Jenkinsfile")))
+			fmt.Fprintln(w, "        }")
+			fmt.Fprintln(w, "    }")
+		}
+	}
+
+	return unhandled
+}
+
+/*
+	Writes the definition of a pipeline with an inline script or a Jenkinsfile from git, and reports if it could.
+	A definition with settings the Job DSL output does not write, e.g. git extensions, is left for the configure
+	block.
+*/
+func writePipelineDefinition(w io.Writer, n *xmlNode) bool {
+	switch n.attr("class") {
+	case "org.jenkinsci.plugins.workflow.cps.CpsFlowDefinition":
+		if !onlyChildren(n, "script", "sandbox") {
+			return false
+		}
+		fmt.Fprintln(w, "    definition {")
+		fmt.Fprintln(w, "        cps {")
+		fmt.Fprintf(w, "            script(%s)\n", groovyString(n.childText("script", "")))
+		fmt.Fprintf(w, "            sandbox(%s)\n", n.childText("sandbox", "false"))
+		fmt.Fprintln(w, "        }")
+		fmt.Fprintln(w, "    }")
+		return true
+	case "org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition":
+		scm := n.child("scm")
+		if scm == nil || scm.attr("class") != "hudson.plugins.git.GitSCM" {
+			return false
+		}
+		if !onlyChildren(n, "scm", "scriptPath", "lightweight") || !onlyChildren(scm, "userRemoteConfigs", "branches") {
+			return false
+		}
+		for _, r := range scm.childNodes("userRemoteConfigs") {
+			if !onlyChildren(r, "url", "credentialsId") {
+				return false
+			}
+		}
+		for _, b := range scm.childNodes("branches") {
+			if !onlyChildren(b, "name") {
+				return false
+			}
+		}
+		fmt.Fprintln(w, "    definition {")
+		fmt.Fprintln(w, "        cpsScm {")
+		fmt.Fprintln(w, "            scm {")
+		fmt.Fprintln(w, "                git {")
+		writeGitRemotes(w, scm, "                    ")
+		fmt.Fprintln(w, "                }")
+		fmt.Fprintln(w, "            }")
+		fmt.Fprintf(w, "            scriptPath(%s)\n", groovyString(n.childText("scriptPath", "Jenkinsfile")))
+		fmt.Fprintf(w, "            lightweight(%s)\n", n.childText("lightweight", "false"))
+		fmt.Fprintln(w, "        }")
+		fmt.Fprintln(w, "    }")
+		return true
+	}
+	return false
+}
+
+func writeGitRemotes(w io.Writer, scm *xmlNode, indent string) {
+	for _, r := range scm.childNodes("userRemoteConfigs") {
+		fmt.Fprintf(w, "%sremote {\n", indent)
+		fmt.Fprintf(w, "%s    url(%s)\n", indent, groovyString(r.childText("url", "")))
+		if creds := r.childText("credentialsId", ""); creds != "" {
+			fmt.Fprintf(w, "%s    credentials(%s)\n", indent, groovyString(creds))
+		}
+		fmt.Fprintf(w, "%s}\n", indent)
+	}
+	for _, b := range scm.childNodes("branches") {
+		fmt.Fprintf(w, "%sbranch(%s)\n", indent, groovyString(b.childText("name", "")))
+	}
+}
+
+/*
+	Reports whether the Job DSL output loses nothing of n by writing only the named children: every other child
+	must be empty, e.g. <extensions/>, or set to its default value.
+*/
+func onlyChildren(n *xmlNode, written ...string) bool {
+	for _, child := range n.Children {
+		if containsString(written, child.Name) {
+			continue
+		}
+		if text := strings.TrimSpace(child.Text); len(child.Children) > 0 || (text != "" && text != jobDSLDefaults[child.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+	Writes the branch sources of a multibranch project if they are all plain git sources, and reports if it could.
+	Sources with traits, e.g. branch discovery or filters, or with branch property strategies are left for the
+	configure block.
+*/
+func writeBranchSources(w io.Writer, n *xmlNode) bool {
+	if !onlyChildren(n, "data") {
+		return false
+	}
+	var remotes []*xmlNode
+	for _, branchSource := range n.childNodes("data") {
+		source := branchSource.child("source")
+		if source == nil || source.attr("class") != "jenkins.plugins.git.GitSCMSource" {
+			return false
+		}
+		if !onlyChildren(branchSource, "source", "strategy") || !onlyChildren(source, "id", "remote", "credentialsId") {
+			return false
+		}
+		if strategy := branchSource.child("strategy"); strategy != nil &&
+			(strategy.attr("class") != "jenkins.branch.DefaultBranchPropertyStrategy" || !onlyChildren(strategy)) {
+			return false
+		}
+		remotes = append(remotes, source)
+	}
+
+	fmt.Fprintln(w, "    branchSources {")
+	for _, s := range remotes {
+		fmt.Fprintln(w, "        git {")
+		if id := s.childText("id", ""); id != "" {
+			fmt.Fprintf(w, "            id(%s)\n", groovyString(id))
+		}
+		fmt.Fprintf(w, "            remote(%s)\n", groovyString(s.childText("remote", "")))
+		if creds := s.childText("credentialsId", ""); creds != "" {
+			fmt.Fprintf(w, "            credentialsId(%s)\n", groovyString(creds))
+		}
+		fmt.Fprintln(w, "        }")
+	}
+	fmt.Fprintln(w, "    }")
+
+	return true
+}
+
+// Writes a configure block replacing each of the elements with its XML from the exported config
+func writeJobDSLConfigure(w io.Writer, nodes []*xmlNode) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "    configure { project ->")
+	fmt.Fprintln(w, "        [")
+	for _, n := range nodes {
+		fmt.Fprintf(w, "            %s,\n", groovyString(strings.TrimSuffix(n.String(), "\n")))
+	}
+	fmt.Fprintln(w, "        ].each { xml ->")
+	fmt.Fprintln(w, "            def node = new XmlParser().parseText(xml)")
+	fmt.Fprintln(w, "            project.children().removeAll { it.name() == node.name() }")
+	fmt.Fprintln(w, "            project << node")
+	fmt.Fprintln(w, "        }")
+	fmt.Fprintln(w, "    }")
+}
+
+// Returns s as a Groovy string literal without interpolation, using a triple-quoted string if it spans lines
+func groovyString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s)
+	if strings.Contains(s, "\n") {
+		return "'''" + s + "'''"
+	}
+	return "'" + s + "'"
+}
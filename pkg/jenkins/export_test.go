@@ -0,0 +1,233 @@
+package jenkins
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteJobDSL(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name: "pipeline from git",
+			config: `<flow-definition plugin="workflow-job@2.40">
+  <description>App</description>
+  <definition class="org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition" plugin="workflow-cps@2.90">
+    <scm class="hudson.plugins.git.GitSCM" plugin="git@4.7.1">
+      <configVersion>2</configVersion>
+      <userRemoteConfigs>
+        <hudson.plugins.git.UserRemoteConfig>
+          <url>https://github.com/acme/app.git</url>
+          <credentialsId>gh-token</credentialsId>
+        </hudson.plugins.git.UserRemoteConfig>
+      </userRemoteConfigs>
+      <branches>
+        <hudson.plugins.git.BranchSpec>
+          <name>*/main</name>
+        </hudson.plugins.git.BranchSpec>
+      </branches>
+      <doGenerateSubmoduleConfigurations>false</doGenerateSubmoduleConfigurations>
+      <submoduleCfg class="empty-list"/>
+      <extensions/>
+    </scm>
+    <scriptPath>ci/Jenkinsfile</scriptPath>
+    <lightweight>true</lightweight>
+  </definition>
+  <disabled>false</disabled>
+</flow-definition>`,
+			want: `// Generated by tronci jenkins jobs export
+
+pipelineJob('team/app') {
+    description('App')
+    definition {
+        cpsScm {
+            scm {
+                git {
+                    remote {
+                        url('https://github.com/acme/app.git')
+                        credentials('gh-token')
+                    }
+                    branch('*/main')
+                }
+            }
+            scriptPath('ci/Jenkinsfile')
+            lightweight(true)
+        }
+    }
+}
+`,
+		},
+		{
+			name: "pipeline from git with extensions",
+			config: `<flow-definition>
+  <definition class="org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition">
+    <scm class="hudson.plugins.git.GitSCM">
+      <userRemoteConfigs>
+        <hudson.plugins.git.UserRemoteConfig>
+          <url>https://github.com/acme/app.git</url>
+        </hudson.plugins.git.UserRemoteConfig>
+      </userRemoteConfigs>
+      <extensions>
+        <hudson.plugins.git.extensions.impl.CleanCheckout/>
+      </extensions>
+    </scm>
+    <scriptPath>Jenkinsfile</scriptPath>
+  </definition>
+</flow-definition>`,
+			want: `// Generated by tronci jenkins jobs export
+
+pipelineJob('team/app') {
+    configure { project ->
+        [
+            '''<definition class="org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition">
+  <scm class="hudson.plugins.git.GitSCM">
+    <userRemoteConfigs>
+      <hudson.plugins.git.UserRemoteConfig>
+        <url>https://github.com/acme/app.git</url>
+      </hudson.plugins.git.UserRemoteConfig>
+    </userRemoteConfigs>
+    <extensions>
+      <hudson.plugins.git.extensions.impl.CleanCheckout/>
+    </extensions>
+  </scm>
+  <scriptPath>Jenkinsfile</scriptPath>
+</definition>''',
+        ].each { xml ->
+            def node = new XmlParser().parseText(xml)
+            project.children().removeAll { it.name() == node.name() }
+            project << node
+        }
+    }
+}
+`,
+		},
+		{
+			name: "pipeline from git with a refspec",
+			config: `<flow-definition>
+  <definition class="org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition">
+    <scm class="hudson.plugins.git.GitSCM">
+      <userRemoteConfigs>
+        <hudson.plugins.git.UserRemoteConfig>
+          <url>https://github.com/acme/app.git</url>
+          <refspec>+refs/pull/*:refs/remotes/origin/pr/*</refspec>
+        </hudson.plugins.git.UserRemoteConfig>
+      </userRemoteConfigs>
+    </scm>
+  </definition>
+</flow-definition>`,
+			want: `// Generated by tronci jenkins jobs export
+
+pipelineJob('team/app') {
+    configure { project ->
+        [
+            '''<definition class="org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition">
+  <scm class="hudson.plugins.git.GitSCM">
+    <userRemoteConfigs>
+      <hudson.plugins.git.UserRemoteConfig>
+        <url>https://github.com/acme/app.git</url>
+        <refspec>+refs/pull/*:refs/remotes/origin/pr/*</refspec>
+      </hudson.plugins.git.UserRemoteConfig>
+    </userRemoteConfigs>
+  </scm>
+</definition>''',
+        ].each { xml ->
+            def node = new XmlParser().parseText(xml)
+            project.children().removeAll { it.name() == node.name() }
+            project << node
+        }
+    }
+}
+`,
+		},
+		{
+			name: "multibranch git source",
+			config: `<org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject>
+  <sources class="jenkins.branch.MultiBranchProject$BranchSourceList">
+    <data>
+      <jenkins.branch.BranchSource>
+        <source class="jenkins.plugins.git.GitSCMSource">
+          <id>app</id>
+          <remote>https://github.com/acme/app.git</remote>
+          <credentialsId>gh-token</credentialsId>
+          <traits/>
+        </source>
+        <strategy class="jenkins.branch.DefaultBranchPropertyStrategy">
+          <properties class="empty-list"/>
+        </strategy>
+      </jenkins.branch.BranchSource>
+    </data>
+    <owner class="org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject" reference="../.."/>
+  </sources>
+</org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject>`,
+			want: `// Generated by tronci jenkins jobs export
+
+multibranchPipelineJob('team/app') {
+    branchSources {
+        git {
+            id('app')
+            remote('https://github.com/acme/app.git')
+            credentialsId('gh-token')
+        }
+    }
+}
+`,
+		},
+		{
+			name: "multibranch git source with traits",
+			config: `<org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject>
+  <sources class="jenkins.branch.MultiBranchProject$BranchSourceList">
+    <data>
+      <jenkins.branch.BranchSource>
+        <source class="jenkins.plugins.git.GitSCMSource">
+          <remote>https://github.com/acme/app.git</remote>
+          <traits>
+            <jenkins.plugins.git.traits.BranchDiscoveryTrait/>
+          </traits>
+        </source>
+      </jenkins.branch.BranchSource>
+    </data>
+  </sources>
+</org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject>`,
+			want: `// Generated by tronci jenkins jobs export
+
+multibranchPipelineJob('team/app') {
+    configure { project ->
+        [
+            '''<sources class="jenkins.branch.MultiBranchProject$BranchSourceList">
+  <data>
+    <jenkins.branch.BranchSource>
+      <source class="jenkins.plugins.git.GitSCMSource">
+        <remote>https://github.com/acme/app.git</remote>
+        <traits>
+          <jenkins.plugins.git.traits.BranchDiscoveryTrait/>
+        </traits>
+      </source>
+    </jenkins.branch.BranchSource>
+  </data>
+</sources>''',
+        ].each { xml ->
+            def node = new XmlParser().parseText(xml)
+            project.children().removeAll { it.name() == node.name() }
+            project << node
+        }
+    }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeJobDSL(&buf, []JobRef{{FullName: "team/app"}}, map[string]string{"team/app": tt.config}); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeJobDSL() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package jenkins
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+type FolderOptions struct {
+	Description        string
+	Parents            bool
+	HealthMetric       string
+	HealthNonRecursive bool
+	// Shared libraries available to jobs in the folder, each given as "name=git-url[@default-version]"
+	Libraries []string
+}
+
+type folderLibrary struct {
+	Name           string
+	Remote         string
+	DefaultVersion string
+}
+
+/*
+	Creates a folder with the description and properties in opts. With opts.Parents, missing parent folders
+	are created as well and an existing folder is not an error.
+*/
+func CreateFolder(c *APIClient, folderURL string, opts FolderOptions) error {
+	fullName := jobFullName(folderURL)
+	if fullName == "" {
+		return fmt.Errorf("a folder name is required")
+	}
+
+	config, err := renderFolderConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	segments := strings.Split(fullName, "/")
+	for i := range segments {
+		name := strings.Join(segments[:i+1], "/")
+		leaf := i == len(segments)-1
+
+		exists, err := jobExists(c, name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if leaf && !opts.Parents {
+				return fmt.Errorf("\"%s\" already exists", name)
+			}
+			continue
+		}
+		if !leaf && !opts.Parents {
+			return fmt.Errorf("parent folder \"%s\" does not exist, use --parents to create it", name)
+		}
+
+		folderConfig := config
+		if !leaf {
+			if folderConfig, err = renderFolderConfig(FolderOptions{HealthMetric: "worst-child"}); err != nil {
+				return err
+			}
+		}
+		if err := createJob(c, name, folderConfig); err != nil {
+			return err
+		}
+		log.Printf("Created folder %s", name)
+	}
+
+	return nil
+}
+
+// ListFolders outputs the folders, multibranch projects and organization folders below folderURL
+func ListFolders(c *APIClient, folderURL string, recursive bool) error {
+	items, err := walkTree(c, jobFullName(folderURL), recursive)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE")
+	for _, item := range items {
+		if item.IsFolder() {
+			fmt.Fprintf(w, "%s\t%s\n", item.FullName, item.Type())
+		}
+	}
+	w.Flush()
+
+	return nil
+}
+
+/*
+	Deletes a folder. A folder that still contains jobs is only deleted if recursive is set, since Jenkins
+	deletes the contents along with it. Asks for confirmation unless yes is set.
+*/
+func DeleteFolder(c *APIClient, folderURL string, recursive bool, yes bool) error {
+	fullName := jobFullName(folderURL)
+	if fullName == "" {
+		return fmt.Errorf("a folder name is required")
+	}
+
+	items, err := walkTree(c, fullName, true)
+	if err != nil {
+		return err
+	}
+	if len(items) == 1 && items[0].FullName == fullName && !items[0].IsFolder() {
+		return fmt.Errorf("\"%s\" is not a folder", fullName)
+	}
+	if len(items) > 0 && !recursive {
+		return fmt.Errorf("folder \"%s\" contains %d items, use --recursive to delete them along with it", fullName, len(items))
+	}
+
+	if !yes && !confirm(fmt.Sprintf("Delete folder %s and the %d items in it?", fullName, len(items))) {
+		return fmt.Errorf("aborted")
+	}
+
+	if err := postForm(c, jobBase(fullName)+"/doDelete", nil); err != nil {
+		return fmt.Errorf("failed to delete \"%s\": %s", fullName, err)
+	}
+	log.Printf("Deleted folder %s", fullName)
+
+	return nil
+}
+
+func renderFolderConfig(opts FolderOptions) (string, error) {
+	if opts.HealthMetric != "worst-child" && opts.HealthMetric != "none" {
+		return "", fmt.Errorf("invalid health metric \"%s\": expected \"worst-child\" or \"none\"", opts.HealthMetric)
+	}
+
+	var libraries []folderLibrary
+	for _, l := range opts.Libraries {
+		name, remote := splitKeyValue(l)
+		if name == "" || remote == "" {
+			return "", fmt.Errorf("invalid library \"%s\": expected \"name=git-url[@default-version]\"", l)
+		}
+
+		lib := folderLibrary{Name: name, Remote: remote}
+		// split the version off at the last "@" unless it belongs to a user@host style remote
+		if i := strings.LastIndex(remote, "@"); i > 0 && !strings.Contains(remote[i:], ":") {
+			lib.Remote, lib.DefaultVersion = remote[:i], remote[i+1:]
+		}
+		libraries = append(libraries, lib)
+	}
+
+	tmpl, err := template.New("folder.xml").Funcs(template.FuncMap{"xml": escapeXMLText}).ParseFS(templates, "templates/folder.xml")
+	if err != nil {
+		log.Fatalf("failed to load folder template: %s", err)
+	}
+
+	data := struct {
+		FolderOptions
+		Libraries []folderLibrary
+	}{opts, libraries}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render folder config: %s", err)
+	}
+
+	return buf.String(), nil
+}
+
+// Splits "key=value" at the first "="
+func splitKeyValue(s string) (string, string) {
+	i := strings.Index(s, "=")
+	if i < 0 {
+		return s, ""
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+}
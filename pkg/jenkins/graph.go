@@ -0,0 +1,359 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Elements of a job config listing the jobs that trigger it
+var upstreamXPaths = []string{"//upstreamProjects"}
+
+// Elements of a job config listing the jobs it triggers: build triggers, parameterized triggers and build pipelines
+var downstreamXPaths = []string{"//childProjects", "//projects", "//downstreamProjectNames"}
+
+/*
+	"build" steps of pipeline scripts, e.g. "build 'deploy'", "build job: '../deploy', wait: false" or
+	"def b = build(propagate: false, job: 'deploy')". Job names containing "$" are built at runtime and skipped.
+*/
+var buildStepRegex = regexp.MustCompile(`(?m)(?:^|[;{=])\s*build\s*(?:\(\s*)?(?:['"]([^'"$\n]+)['"]|[^)\n]*?\bjob\s*:\s*['"]([^'"$\n]+)['"])`)
+
+type JobGraphOptions struct {
+	Folder string
+	// "dot", "mermaid" or "json"
+	Format string
+}
+
+// JobGraph is the static dependency graph of jobs, with edges from the triggering job to the triggered job
+type JobGraph struct {
+	Nodes  []GraphNode `json:"nodes"`
+	Edges  []GraphEdge `json:"edges"`
+	Cycles [][]string  `json:"cycles"`
+	// References to jobs that do not exist, e.g. deleted jobs
+	Dangling []GraphEdge `json:"dangling"`
+}
+
+type GraphNode struct {
+	Name string `json:"name"`
+	// The job is outside the folder the graph was built for
+	External bool `json:"external,omitempty"`
+	// The job does not exist
+	Dangling bool `json:"dangling,omitempty"`
+}
+
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// "trigger" for build triggers, "build" for build steps of pipeline scripts
+	Kind string `json:"kind"`
+}
+
+/*
+	Builds the static dependency graph of the jobs in a folder, recursively, from the upstream and downstream
+	projects of their triggers and the jobs started by "build" steps of their pipeline scripts, and outputs it
+	in DOT, Mermaid or JSON format. Only jobs with dependencies are included. Cycles and references to jobs that
+	no longer exist are reported, on stderr for the DOT and Mermaid formats.
+*/
+func GraphJobs(c *APIClient, opts JobGraphOptions) error {
+	switch opts.Format {
+	case "dot", "mermaid", "json":
+	default:
+		return fmt.Errorf("invalid output format \"%s\": expected dot, mermaid or json", opts.Format)
+	}
+
+	items, configs, err := getComparableItems(c, jobFullName(opts.Folder))
+	if err != nil {
+		return err
+	}
+
+	graph, err := buildJobGraph(c, items, configs)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(graph)
+	case "dot":
+		printDOTGraph(graph)
+	case "mermaid":
+		printMermaidGraph(graph)
+	}
+
+	for _, cycle := range graph.Cycles {
+		log.Printf("Cycle: %s", strings.Join(cycle, " -> "))
+	}
+	for _, e := range graph.Dangling {
+		log.Printf("Dangling reference: %s refers to %s, which does not exist", e.From, e.To)
+	}
+
+	return nil
+}
+
+// Returns the graph of the references between the jobs in items, checking whether the other referenced jobs exist
+func buildJobGraph(c *APIClient, items map[string]JobRef, configs map[string]string) (*JobGraph, error) {
+	graph := &JobGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Cycles: [][]string{}, Dangling: []GraphEdge{}}
+
+	// whether referenced jobs outside of items exist
+	exists := make(map[string]bool)
+	nodes := make(map[string]GraphNode)
+	seen := make(map[GraphEdge]bool)
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, dep := range jobDependencies(name, configs[name]) {
+			ref, ok := resolveJobName(dep.ref, name, items)
+			if ref == "" {
+				continue
+			}
+			node := GraphNode{Name: ref}
+			if !ok {
+				// e.g. a job at the root referred to by its name from a job in the folder
+				node.Dangling = true
+				for _, candidate := range jobNameCandidates(dep.ref, name) {
+					if _, checked := exists[candidate]; !checked {
+						found, err := jobExists(c, candidate)
+						if err != nil {
+							return nil, err
+						}
+						exists[candidate] = found
+					}
+					if exists[candidate] {
+						ref = candidate
+						node = GraphNode{Name: ref, External: true}
+						break
+					}
+				}
+			}
+
+			edge := GraphEdge{From: name, To: ref, Kind: dep.kind}
+			if dep.upstream {
+				edge.From, edge.To = ref, name
+			}
+			if seen[edge] {
+				continue
+			}
+			seen[edge] = true
+
+			nodes[name] = GraphNode{Name: name}
+			nodes[ref] = node
+			graph.Edges = append(graph.Edges, edge)
+			if node.Dangling {
+				graph.Dangling = append(graph.Dangling, GraphEdge{From: name, To: ref, Kind: dep.kind})
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		graph.Nodes = append(graph.Nodes, n)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Name < graph.Nodes[j].Name })
+	graph.Cycles = findCycles(graph.Edges)
+
+	return graph, nil
+}
+
+// A job referenced by a job config, as written in the config
+type jobDependency struct {
+	ref  string
+	kind string
+	// The referenced job triggers the job, rather than being triggered by it
+	upstream bool
+}
+
+// Returns the jobs a job config refers to in its triggers and in the build steps of its pipeline script
+func jobDependencies(fullName string, config string) []jobDependency {
+	root, err := parseXML(config)
+	if err != nil {
+		return nil
+	}
+
+	var deps []jobDependency
+	for _, x := range []struct {
+		exprs    []string
+		upstream bool
+	}{{upstreamXPaths, true}, {downstreamXPaths, false}} {
+		for _, expr := range x.exprs {
+			xp, _ := compileXPath(expr)
+			for _, n := range xp.Select(root) {
+				for _, ref := range jobListSplitRegex.Split(strings.TrimSpace(n.Text), -1) {
+					deps = append(deps, jobDependency{ref: ref, kind: "trigger", upstream: x.upstream})
+				}
+			}
+		}
+	}
+
+	xp, _ := compileXPath("//definition/script")
+	for _, n := range xp.Select(root) {
+		for _, m := range buildStepRegex.FindAllStringSubmatch(n.Text, -1) {
+			ref := m[1]
+			if ref == "" {
+				ref = m[2]
+			}
+			deps = append(deps, jobDependency{ref: ref, kind: "build"})
+		}
+	}
+
+	return deps
+}
+
+/*
+	Returns the cycles of the graph, each as the path of jobs from a job back to itself. A cycle is reported once,
+	starting from its first job in alphabetical order.
+*/
+func findCycles(edges []GraphEdge) [][]string {
+	adjacent := make(map[string][]string)
+	var names []string
+	for _, e := range edges {
+		if _, ok := adjacent[e.From]; !ok {
+			names = append(names, e.From)
+		}
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+	}
+	sort.Strings(names)
+	for _, next := range adjacent {
+		sort.Strings(next)
+	}
+
+	cycles := [][]string{}
+	reported := make(map[string]bool)
+	state := make(map[string]int) // 1 while on the path, 2 once visited
+	var path []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = 1
+		path = append(path, name)
+		for _, next := range adjacent[name] {
+			switch state[next] {
+			case 0:
+				visit(next)
+			case 1:
+				start := len(path) - 1
+				for path[start] != next {
+					start--
+				}
+				cycle := rotateCycle(path[start:])
+				key := strings.Join(cycle, "\x00")
+				if !reported[key] {
+					reported[key] = true
+					cycles = append(cycles, append(cycle, cycle[0]))
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = 2
+	}
+	for _, name := range names {
+		if state[name] == 0 {
+			visit(name)
+		}
+	}
+
+	return cycles
+}
+
+// Returns a copy of the cycle starting from its smallest job name
+func rotateCycle(cycle []string) []string {
+	first := 0
+	for i, name := range cycle {
+		if name < cycle[first] {
+			first = i
+		}
+	}
+	return append(append([]string{}, cycle[first:]...), cycle[:first]...)
+}
+
+// Returns the edges of the graph that belong to a cycle
+func cycleEdges(graph *JobGraph) map[[2]string]bool {
+	edges := make(map[[2]string]bool)
+	for _, cycle := range graph.Cycles {
+		for i := 0; i+1 < len(cycle); i++ {
+			edges[[2]string{cycle[i], cycle[i+1]}] = true
+		}
+	}
+	return edges
+}
+
+func printDOTGraph(graph *JobGraph) {
+	inCycle := cycleEdges(graph)
+
+	fmt.Println("digraph jobs {")
+	fmt.Println("  rankdir=LR;")
+	fmt.Println("  node [shape=box];")
+	for _, n := range graph.Nodes {
+		switch {
+		case n.Dangling:
+			fmt.Printf("  %s [style=dashed, color=red, label=%s];\n", dotID(n.Name), dotID(n.Name+`\n(missing)`))
+		case n.External:
+			fmt.Printf("  %s [style=dotted];\n", dotID(n.Name))
+		default:
+			fmt.Printf("  %s;\n", dotID(n.Name))
+		}
+	}
+	for _, e := range graph.Edges {
+		attrs := []string{"label=" + e.Kind}
+		if e.Kind == "build" {
+			attrs = append(attrs, "style=dashed")
+		}
+		if inCycle[[2]string{e.From, e.To}] {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Printf("  %s -> %s [%s];\n", dotID(e.From), dotID(e.To), strings.Join(attrs, ", "))
+	}
+	fmt.Println("}")
+}
+
+func printMermaidGraph(graph *JobGraph) {
+	inCycle := cycleEdges(graph)
+
+	// job names are not valid Mermaid IDs
+	ids := make(map[string]string)
+	fmt.Println("graph LR")
+	for i, n := range graph.Nodes {
+		ids[n.Name] = fmt.Sprintf("n%d", i)
+		label := strings.ReplaceAll(n.Name, `"`, "#quot;")
+		switch {
+		case n.Dangling:
+			fmt.Printf("  %s[\"%s (missing)\"]:::dangling\n", ids[n.Name], label)
+		case n.External:
+			fmt.Printf("  %s[\"%s\"]:::external\n", ids[n.Name], label)
+		default:
+			fmt.Printf("  %s[\"%s\"]\n", ids[n.Name], label)
+		}
+	}
+
+	var cycleLinks []string
+	for i, e := range graph.Edges {
+		arrow := "-->"
+		if e.Kind == "build" {
+			arrow = "-.->"
+		}
+		fmt.Printf("  %s %s|%s| %s\n", ids[e.From], arrow, e.Kind, ids[e.To])
+		if inCycle[[2]string{e.From, e.To}] {
+			cycleLinks = append(cycleLinks, fmt.Sprint(i))
+		}
+	}
+
+	fmt.Println("  classDef dangling stroke:#d00,stroke-dasharray:5 5")
+	fmt.Println("  classDef external stroke-dasharray:2 2")
+	if len(cycleLinks) > 0 {
+		fmt.Printf("  linkStyle %s stroke:#d00\n", strings.Join(cycleLinks, ","))
+	}
+}
+
+// Returns the name quoted as a DOT identifier
+func dotID(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
@@ -0,0 +1,110 @@
+package jenkins
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindCycles(t *testing.T) {
+	edge := func(from, to string) GraphEdge { return GraphEdge{From: from, To: to, Kind: "trigger"} }
+
+	tests := []struct {
+		name  string
+		edges []GraphEdge
+		want  [][]string
+	}{
+		{"no edges", nil, [][]string{}},
+		{"chain", []GraphEdge{edge("a", "b"), edge("b", "c")}, [][]string{}},
+		{"diamond", []GraphEdge{edge("a", "b"), edge("a", "c"), edge("b", "d"), edge("c", "d")}, [][]string{}},
+		{"self reference", []GraphEdge{edge("a", "a")}, [][]string{{"a", "a"}}},
+		{"two jobs", []GraphEdge{edge("b", "a"), edge("a", "b")}, [][]string{{"a", "b", "a"}}},
+		{
+			"reported from the first job",
+			[]GraphEdge{edge("c", "a"), edge("a", "b"), edge("b", "c"), edge("x", "b")},
+			[][]string{{"a", "b", "c", "a"}},
+		},
+		{
+			"separate cycles",
+			[]GraphEdge{edge("a", "b"), edge("b", "a"), edge("c", "d"), edge("d", "c"), edge("b", "c")},
+			[][]string{{"a", "b", "a"}, {"c", "d", "c"}},
+		},
+		{
+			"shared job",
+			[]GraphEdge{edge("a", "b"), edge("b", "a"), edge("b", "c"), edge("c", "a")},
+			[][]string{{"a", "b", "a"}, {"a", "b", "c", "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findCycles(tt.edges); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findCycles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobDependencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []jobDependency
+	}{
+		{
+			"triggers",
+			`<project><triggers><jenkins.triggers.ReverseBuildTrigger><upstreamProjects>a, ../b</upstreamProjects>` +
+				`</jenkins.triggers.ReverseBuildTrigger></triggers><publishers><hudson.tasks.BuildTrigger>` +
+				`<childProjects>c</childProjects></hudson.tasks.BuildTrigger></publishers></project>`,
+			[]jobDependency{
+				{ref: "a", kind: "trigger", upstream: true},
+				{ref: "../b", kind: "trigger", upstream: true},
+				{ref: "c", kind: "trigger"},
+			},
+		},
+		{
+			"build steps",
+			`<flow-definition><definition><script>node {
+  build 'a'
+  build job: '../b', wait: false
+  def r = build(propagate: false, job: "/c")
+  build "deploy-${env.TARGET}"
+  rebuild 'x'
+}</script></definition></flow-definition>`,
+			[]jobDependency{
+				{ref: "a", kind: "build"},
+				{ref: "../b", kind: "build"},
+				{ref: "/c", kind: "build"},
+			},
+		},
+		{"no dependencies", `<project><builders/></project>`, nil},
+		{"invalid config", `<project>`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jobDependencies("team/app", tt.config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("jobDependencies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobNameCandidates(t *testing.T) {
+	tests := []struct {
+		ref, fullName string
+		want          []string
+	}{
+		{"deploy", "team/app", []string{"team/deploy", "deploy"}},
+		{"deploy", "app", []string{"deploy"}},
+		{"../deploy", "team/sub/app", []string{"team/deploy"}},
+		{"./deploy", "team/app", []string{"team/deploy"}},
+		{"/ops/deploy", "team/app", []string{"ops/deploy"}},
+		{" ", "team/app", nil},
+	}
+
+	for _, tt := range tests {
+		if got := jobNameCandidates(tt.ref, tt.fullName); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("jobNameCandidates(%q, %q) = %q, want %q", tt.ref, tt.fullName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Number of most recent builds of a running pipeline that are checked for pending input
+const inputBuildsChecked = 20
+
+// PendingInput is an input step a pipeline build is paused on
+type PendingInput struct {
+	Job        string                `json:"job"`
+	Build      int64                 `json:"build"`
+	URL        string                `json:"url"`
+	ID         string                `json:"id"`
+	Message    string                `json:"message"`
+	Proceed    string                `json:"proceedText"`
+	Parameters []ParameterDefinition `json:"parameters,omitempty"`
+}
+
+type InputResponse struct {
+	// Input step ID; may be left empty if the build is paused on a single input
+	InputID string
+	// Parameter values as KEY=VALUE
+	Params []string
+	Abort  bool
+}
+
+/*
+	Lists the input steps that running pipelines in a folder are paused on, recursively. Only the most recent
+	builds of the pipelines that are currently building are checked.
+*/
+func ListPendingInputs(c *APIClient, folderURL string, output string) error {
+	jobs, err := walkJobs(c, jobFullName(folderURL))
+	if err != nil {
+		return err
+	}
+
+	var inputs []PendingInput
+	for _, j := range jobs {
+		if j.Type() != "pipeline" || !strings.HasSuffix(j.Color, "_anime") {
+			continue
+		}
+
+		var job struct {
+			Builds []buildSummary `json:"builds"`
+		}
+		query := map[string]string{"tree": fmt.Sprintf("builds[number,building]{0,%d}", inputBuildsChecked)}
+		resp, err := c.Client.Requester.GetJSON(c.Context, jobBase(j.FullName), &job, query)
+		if err != nil {
+			return fmt.Errorf("unable to list builds of \"%s\": %s", j.FullName, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unable to list builds of \"%s\": %s", j.FullName, resp.Status)
+		}
+		for _, b := range job.Builds {
+			if !b.Building {
+				continue
+			}
+			pending, err := getPendingInputs(c, j.FullName, b.Number)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, pending...)
+		}
+	}
+
+	switch output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if inputs == nil {
+			inputs = []PendingInput{}
+		}
+		return enc.Encode(inputs)
+	case "text", "":
+	default:
+		return fmt.Errorf("invalid output format \"%s\": expected text or json", output)
+	}
+
+	if len(inputs) == 0 {
+		fmt.Println("No builds are waiting for input")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB\tBUILD\tINPUT ID\tMESSAGE\tPARAMETERS\tURL")
+	for _, in := range inputs {
+		var params []string
+		for _, p := range in.Parameters {
+			params = append(params, p.Name)
+		}
+		message := strings.Join(strings.Fields(in.Message), " ")
+		fmt.Fprintf(w, "%s\t#%d\t%s\t%s\t%s\t%s\n", in.Job, in.Build, in.ID, snippet(message, nil), strings.Join(params, ","), in.URL)
+	}
+	w.Flush()
+
+	return nil
+}
+
+/*
+	Approves or aborts an input step of a paused pipeline build. Parameter values are validated against the
+	parameters of the input step before it is submitted.
+*/
+func RespondToInput(c *APIClient, jobURL string, id int64, resp InputResponse) error {
+	fullName := jobFullName(jobURL)
+
+	pending, err := getPendingInputs(c, fullName, id)
+	if err != nil {
+		return err
+	}
+	input, err := selectInput(pending, resp.InputID, fullName, id)
+	if err != nil {
+		return err
+	}
+
+	base := fmt.Sprintf("%s/%d/input/%s", jobBase(fullName), id, url.PathEscape(input.ID))
+	label := fmt.Sprintf("input \"%s\" of %s #%d", input.ID, fullName, id)
+
+	if resp.Abort {
+		if len(resp.Params) > 0 {
+			return fmt.Errorf("parameters cannot be given when aborting an input")
+		}
+		if err := postForm(c, base+"/abort", nil); err != nil {
+			return fmt.Errorf("failed to abort %s: %s", label, err)
+		}
+		log.Printf("Aborted %s", label)
+		return nil
+	}
+
+	params, err := parseParameters(resp.Params)
+	if err != nil {
+		return err
+	}
+	if err := validateParameters(label, input.Parameters, params); err != nil {
+		return err
+	}
+
+	if len(input.Parameters) == 0 {
+		if err := postForm(c, base+"/proceedEmpty", nil); err != nil {
+			return fmt.Errorf("failed to approve %s: %s", label, err)
+		}
+		log.Printf("Approved %s", label)
+		return nil
+	}
+
+	var values []map[string]interface{}
+	for _, p := range input.Parameters {
+		v, ok := params[p.Name]
+		if !ok {
+			// parameters that are not given take their default value
+			if p.Default == nil {
+				continue
+			}
+			values = append(values, map[string]interface{}{"name": p.Name, "value": p.Default})
+			continue
+		}
+		var value interface{} = v
+		if p.Type == "boolean" {
+			value = v == "true"
+		}
+		values = append(values, map[string]interface{}{"name": p.Name, "value": value})
+	}
+	payload, _ := json.Marshal(map[string]interface{}{"parameter": values})
+
+	form := url.Values{}
+	form.Set("json", string(payload))
+	form.Set("proceed", input.Proceed)
+	if err := postForm(c, base+"/submit", form); err != nil {
+		return fmt.Errorf("failed to approve %s: %s", label, err)
+	}
+	log.Printf("Approved %s", label)
+
+	return nil
+}
+
+// Returns the input steps a build is paused on, from the pipeline REST API
+func getPendingInputs(c *APIClient, fullName string, number int64) ([]PendingInput, error) {
+	var actions []struct {
+		ID          string `json:"id"`
+		Message     string `json:"message"`
+		ProceedText string `json:"proceedText"`
+		Inputs      []struct {
+			Name        string `json:"name"`
+			Type        string `json:"type"`
+			Description string `json:"description"`
+			Definition  struct {
+				Choices               []string `json:"choices"`
+				DefaultParameterValue *struct {
+					Value interface{} `json:"value"`
+				} `json:"defaultParameterValue"`
+			} `json:"definition"`
+		} `json:"inputs"`
+	}
+
+	var body string
+	base := fmt.Sprintf("%s/%d", jobBase(fullName), number)
+	resp, err := c.Client.Requester.Get(c.Context, base+"/wfapi/pendingInputActions", &body, map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve pending input of %s #%d: %s", fullName, number, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to retrieve pending input of %s #%d: %s", fullName, number, resp.Status)
+	}
+	if err := json.Unmarshal([]byte(body), &actions); err != nil {
+		return nil, fmt.Errorf("unable to retrieve pending input of %s #%d: %s", fullName, number, err)
+	}
+
+	var inputs []PendingInput
+	for _, a := range actions {
+		in := PendingInput{
+			Job:     fullName,
+			Build:   number,
+			URL:     fmt.Sprintf("%s%s/input/", strings.TrimSuffix(c.Client.Server, "/"), base),
+			ID:      a.ID,
+			Message: a.Message,
+			Proceed: a.ProceedText,
+		}
+		for _, p := range a.Inputs {
+			def := ParameterDefinition{
+				Name:        p.Name,
+				Type:        parameterType(p.Type),
+				Description: p.Description,
+				Choices:     p.Definition.Choices,
+			}
+			if p.Definition.DefaultParameterValue != nil {
+				def.Default = p.Definition.DefaultParameterValue.Value
+			}
+			in.Parameters = append(in.Parameters, def)
+		}
+		inputs = append(inputs, in)
+	}
+
+	return inputs, nil
+}
+
+// Returns the pending input with the specified ID, or the only pending input if id is empty
+func selectInput(pending []PendingInput, id string, fullName string, number int64) (PendingInput, error) {
+	if len(pending) == 0 {
+		return PendingInput{}, fmt.Errorf("%s #%d is not waiting for input", fullName, number)
+	}
+
+	var ids []string
+	for _, in := range pending {
+		if in.ID == id || (id == "" && len(pending) == 1) {
+			return in, nil
+		}
+		ids = append(ids, in.ID)
+	}
+
+	if id == "" {
+		return PendingInput{}, fmt.Errorf("%s #%d is waiting for several inputs, use --input-id to select one of %s", fullName, number, strings.Join(ids, ", "))
+	}
+	return PendingInput{}, fmt.Errorf("%s #%d has no pending input \"%s\" (pending: %s)", fullName, number, id, strings.Join(ids, ", "))
+}
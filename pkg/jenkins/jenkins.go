@@ -50,6 +50,7 @@ func NewJenkinsClient(jenkinsURL string, creds Credentials, debug bool) *APIClie
 
 	jenkinsClient.Client = jenkins
 	jenkinsClient.Context = ctx
+	jenkinsClient.DebugMode = debug
 
 	return &jenkinsClient
 }
@@ -73,6 +74,7 @@ type BuildInfo struct {
 	CompletedTimeUnix      int64
 	DurationMs             int64
 	ExecutionTimeMs        int64
+	QueueTimeMs            *int64 // nil if the metrics plugin is not installed, or for builds cached before it was recorded
 	AgentHostMachine       string
 }
 
@@ -83,6 +85,11 @@ func (bi *BuildInfo) PrintBuildInfo() {
 	fmt.Printf("  Scheduled at: %s\n", bi.ScheduledTimestamp.String())
 	fmt.Printf("  Began executing at: %s\n", time.Unix(bi.ExecutionStartTimeUnix, 0))
 	fmt.Printf("  Ended: %s\n", time.Unix(bi.CompletedTimeUnix, 0))
+	if bi.QueueTimeMs != nil {
+		fmt.Printf("  Queue Time(s): %d\n", *bi.QueueTimeMs/int64(1000))
+	} else {
+		fmt.Println("  Queue Time(s): unknown")
+	}
 	fmt.Printf("  Execution Time(s): %d\n", bi.ExecutionTimeMs/int64(1000))
 	fmt.Printf("  Total Duration(s): %d\n", bi.DurationMs/int64(1000))
 }
@@ -110,13 +117,7 @@ func GetBuildInfo(jobURL string, id int64, jc *APIClient) (BuildInfo, error) {
 		return buildInfo, fmt.Errorf("failed to retrieve build: %s", err)
 	}
 
-	buildInfo.JobName = job.GetDetails().FullName
-	buildInfo.BuildID = build.GetBuildNumber()
-	buildInfo.ScheduledTimestamp = build.GetTimestamp()
-	buildInfo.DurationMs = int64(math.Round(build.GetDuration()))
-	buildInfo.ExecutionTimeMs = build.GetExecutionTimeMs()
-	buildInfo.CompletedTimeUnix = buildInfo.ScheduledTimestamp.Unix() + (int64(buildInfo.DurationMs) / 1000)
-	buildInfo.ExecutionStartTimeUnix = buildInfo.ScheduledTimestamp.Unix() + (int64(buildInfo.DurationMs-buildInfo.ExecutionTimeMs) / 1000)
+	buildInfo = newBuildInfo(job, build)
 	buildInfo.AgentHostMachine, err = findBuildHostMachineName(build, jc)
 
 	if err != nil {
@@ -129,6 +130,25 @@ func GetBuildInfo(jobURL string, id int64, jc *APIClient) (BuildInfo, error) {
 	return buildInfo, nil
 }
 
+/*
+	Returns the timing information for a build. AgentHostMachine is left empty since determining
+	it may require downloading the build log.
+*/
+func newBuildInfo(job *gojenkins.Job, build *gojenkins.Build) BuildInfo {
+	var buildInfo BuildInfo
+
+	buildInfo.JobName = job.GetDetails().FullName
+	buildInfo.BuildID = build.GetBuildNumber()
+	buildInfo.ScheduledTimestamp = build.GetTimestamp()
+	buildInfo.DurationMs = int64(math.Round(build.GetDuration()))
+	buildInfo.ExecutionTimeMs = build.GetExecutionTimeMs()
+	buildInfo.QueueTimeMs = getQueueTimeMs(build)
+	buildInfo.CompletedTimeUnix = buildInfo.ScheduledTimestamp.Unix() + (int64(buildInfo.DurationMs) / 1000)
+	buildInfo.ExecutionStartTimeUnix = buildInfo.ScheduledTimestamp.Unix() + (int64(buildInfo.DurationMs-buildInfo.ExecutionTimeMs) / 1000)
+
+	return buildInfo
+}
+
 func GetVersion(c *APIClient) {
 	fmt.Printf(c.Client.Version)
 }
@@ -241,6 +261,20 @@ func ListSystemCredentials(c *APIClient, decrypt bool) error {
 	return nil
 }
 
+/*
+	Returns the time in milliseconds the build spent waiting in the queue, as reported by the metrics plugin.
+	Returns nil if the metrics plugin is not installed.
+*/
+func getQueueTimeMs(build *gojenkins.Build) *int64 {
+	for _, a := range build.GetActions() {
+		if a.Class == "jenkins.metrics.impl.TimeInQueueAction" {
+			ms := a.WaitingDurationMillis + a.BlockedDurationMillis + a.BuildableTimeMillis
+			return &ms
+		}
+	}
+	return nil
+}
+
 /*
 	Returns a string indicating the hostname of the machine where this build ran.
 */
@@ -0,0 +1,136 @@
+package jenkins
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Response of the declarative pipeline validator for a valid Jenkinsfile
+const jenkinsfileValidMessage = "Jenkinsfile successfully validated."
+
+// Errors of the declarative pipeline validator, e.g. "WorkflowScript: 3: Expected a stage @ line 3, column 5."
+var jenkinsfileErrorRegex = regexp.MustCompile(`^WorkflowScript: \d+: (.*?)(?: @ line (\d+), column (\d+)\.)?$`)
+
+// Directories that are not searched for Jenkinsfiles
+var jenkinsfileSkipDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+/*
+	Validates Jenkinsfiles with the declarative pipeline validator of the jenkins instance and prints their
+	errors as "file:line:column: message". With all set, each path is a directory searched recursively for
+	Jenkinsfiles. Returns an error if any Jenkinsfile is invalid.
+*/
+func LintJenkinsfiles(c *APIClient, paths []string, all bool) error {
+	files := paths
+	if all {
+		var err error
+		if files, err = findJenkinsfiles(paths); err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no Jenkinsfiles found in %s", strings.Join(paths, ", "))
+		}
+	}
+
+	invalid := 0
+	for _, file := range files {
+		errs, err := validateJenkinsfile(c, file)
+		if err != nil {
+			return err
+		}
+		if len(errs) == 0 {
+			fmt.Printf("%s: ok\n", file)
+			continue
+		}
+		for _, e := range errs {
+			fmt.Println(e)
+		}
+		invalid++
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d Jenkinsfiles are invalid", invalid, len(files))
+	}
+
+	return nil
+}
+
+// Returns the errors found by the validator in a Jenkinsfile, prefixed with the file name and line number
+func validateJenkinsfile(c *APIClient, file string) ([]string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Jenkinsfile: %s", err)
+	}
+
+	form := url.Values{}
+	form.Set("jenkinsfile", string(content))
+
+	var body string
+	resp, err := c.Client.Requester.PostSimple(c.Context, "/pipeline-model-converter/validate", bytes.NewBufferString(form.Encode()), &body, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate %s: %s", file, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to validate %s: %s", file, resp.Status)
+	}
+
+	body = strings.TrimSpace(body)
+	if body == jenkinsfileValidMessage {
+		return nil, nil
+	}
+
+	var errs []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		m := jenkinsfileErrorRegex.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+			// the header of the error list, the source lines quoted under each error, and the error count
+			continue
+		case m[2] != "":
+			errs = append(errs, fmt.Sprintf("%s:%s:%s: %s", file, m[2], m[3], m[1]))
+		default:
+			errs = append(errs, fmt.Sprintf("%s: %s", file, m[1]))
+		}
+	}
+	if len(errs) == 0 {
+		// a response in another format, e.g. for a Jenkinsfile that is not a declarative pipeline
+		errs = append(errs, fmt.Sprintf("%s: %s", file, strings.Join(strings.Fields(body), " ")))
+	}
+
+	return errs, nil
+}
+
+// Returns the Jenkinsfiles in the directories, e.g. "Jenkinsfile", "Jenkinsfile.release" or "deploy.jenkinsfile"
+func findJenkinsfiles(dirs []string) ([]string, error) {
+	var files []string
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			name := info.Name()
+			if info.IsDir() {
+				if path != dir && (strings.HasPrefix(name, ".") || jenkinsfileSkipDirs[name]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if name == "Jenkinsfile" || strings.HasPrefix(name, "Jenkinsfile.") || strings.HasSuffix(strings.ToLower(name), ".jenkinsfile") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to search \"%s\": %s", dir, err)
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,155 @@
+package jenkins
+
+import (
+	"fmt"
+	"sort"
+)
+
+type JobDiffOptions struct {
+	Folder string
+	// Names of the two instances in the report, e.g. their contexts
+	FromName string
+	ToName   string
+	// Element names, or attribute names prefixed with "@", left out of the comparison
+	Ignore []string
+}
+
+// Elements and attributes that differ between instances without reflecting a difference in job definitions
+var DefaultJobDiffIgnore = []string{"nextBuildNumber", "@plugin"}
+
+/*
+	Compares the jobs and folders in a folder of two jenkins instances. Reports the items that exist on only one
+	side and the normalized config diff of the others, leaving out the elements and attributes in opts.Ignore.
+	The contents of multibranch projects and organization folders are not compared.
+*/
+func DiffJobs(from *APIClient, to *APIClient, opts JobDiffOptions) error {
+	folder := jobFullName(opts.Folder)
+
+	fromItems, fromConfigs, err := getComparableItems(from, folder)
+	if err != nil {
+		return fmt.Errorf("%s: %s", opts.FromName, err)
+	}
+	toItems, toConfigs, err := getComparableItems(to, folder)
+	if err != nil {
+		return fmt.Errorf("%s: %s", opts.ToName, err)
+	}
+
+	var onlyFrom, onlyTo, common []string
+	for name := range fromItems {
+		if _, ok := toItems[name]; ok {
+			common = append(common, name)
+		} else {
+			onlyFrom = append(onlyFrom, name)
+		}
+	}
+	for name := range toItems {
+		if _, ok := fromItems[name]; !ok {
+			onlyTo = append(onlyTo, name)
+		}
+	}
+	sort.Strings(onlyFrom)
+	sort.Strings(onlyTo)
+	sort.Strings(common)
+
+	for _, side := range []struct {
+		name  string
+		items []string
+		refs  map[string]JobRef
+	}{{opts.FromName, onlyFrom, fromItems}, {opts.ToName, onlyTo, toItems}} {
+		if len(side.items) == 0 {
+			continue
+		}
+		fmt.Printf("Only in %s (%d):\n", side.name, len(side.items))
+		for _, name := range side.items {
+			fmt.Printf("  %s (%s)\n", name, side.refs[name].Type())
+		}
+		fmt.Println()
+	}
+
+	different := 0
+	for _, name := range common {
+		if fromItems[name].Class != toItems[name].Class {
+			fmt.Printf("%s is a %s in %s and a %s in %s\n\n", name, fromItems[name].Type(), opts.FromName, toItems[name].Type(), opts.ToName)
+			different++
+			continue
+		}
+
+		fromConfig, err := comparableConfig(fromConfigs[name], opts.Ignore)
+		if err != nil {
+			return fmt.Errorf("%s: config of \"%s\": %s", opts.FromName, name, err)
+		}
+		toConfig, err := comparableConfig(toConfigs[name], opts.Ignore)
+		if err != nil {
+			return fmt.Errorf("%s: config of \"%s\": %s", opts.ToName, name, err)
+		}
+
+		if diff := unifiedDiff(fromConfig, toConfig, opts.FromName+"/"+name, opts.ToName+"/"+name, 3); diff != "" {
+			fmt.Println(diff)
+			different++
+		}
+	}
+
+	fmt.Printf("%d identical, %d different, %d only in %s, %d only in %s\n",
+		len(common)-different, different, len(onlyFrom), opts.FromName, len(onlyTo), opts.ToName)
+
+	return nil
+}
+
+// Returns the jobs and folders below folder that are not generated by branch indexing, along with their configs
+func getComparableItems(c *APIClient, folder string) (map[string]JobRef, map[string]string, error) {
+	items, err := walkTree(c, folder, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs := make(map[string]JobRef)
+	var comparable []JobRef
+	for _, item := range items {
+		if !isComputedChild(item.FullName, items) {
+			refs[item.FullName] = item
+			comparable = append(comparable, item)
+		}
+	}
+
+	configs, err := getItemConfigs(c, comparable)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return refs, configs, nil
+}
+
+// Returns the normalized config without the ignored elements and attributes
+func comparableConfig(config string, ignore []string) (string, error) {
+	root, err := parseXML(config)
+	if err != nil {
+		return "", err
+	}
+
+	ignored := make(map[string]bool)
+	for _, name := range ignore {
+		ignored[name] = true
+	}
+	root.removeIgnored(ignored)
+
+	return root.String(), nil
+}
+
+func (n *xmlNode) removeIgnored(ignored map[string]bool) {
+	attrs := n.Attrs[:0]
+	for _, a := range n.Attrs {
+		if !ignored["@"+a.Name.Local] {
+			attrs = append(attrs, a)
+		}
+	}
+	n.Attrs = attrs
+
+	children := n.Children[:0]
+	for _, child := range n.Children {
+		if !ignored[child.Name] {
+			child.removeIgnored(ignored)
+			children = append(children, child)
+		}
+	}
+	n.Children = children
+}
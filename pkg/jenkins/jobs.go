@@ -0,0 +1,400 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const jobTreeFields = "_class,name,fullName,url,color,lastBuild[number,result,timestamp]"
+
+// Maximum number of simultaneous requests made while walking the job tree
+const walkConcurrency = 8
+
+// JobRef identifies a job or folder found while walking the job tree.
+type JobRef struct {
+	Class     string        `json:"_class"`
+	Name      string        `json:"name"`
+	FullName  string        `json:"fullName"`
+	URL       string        `json:"url"`
+	Color     string        `json:"color,omitempty"`
+	LastBuild *buildSummary `json:"lastBuild,omitempty"`
+	Jobs      []JobRef      `json:"jobs,omitempty"`
+}
+
+// IsFolder reports whether the item can contain other jobs, e.g. folders and multibranch projects.
+func (r JobRef) IsFolder() bool {
+	switch r.Class {
+	case "com.cloudbees.hudson.plugins.folder.Folder",
+		"jenkins.branch.OrganizationFolder",
+		"org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject":
+		return true
+	}
+	return false
+}
+
+// Type returns a short name for the kind of item, e.g. "pipeline" or "folder"
+func (r JobRef) Type() string {
+	switch r.Class {
+	case "org.jenkinsci.plugins.workflow.job.WorkflowJob":
+		return "pipeline"
+	case "hudson.model.FreeStyleProject":
+		return "freestyle"
+	case "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject":
+		return "multibranch"
+	case "com.cloudbees.hudson.plugins.folder.Folder":
+		return "folder"
+	case "jenkins.branch.OrganizationFolder":
+		return "organization"
+	case "hudson.matrix.MatrixProject":
+		return "matrix"
+	}
+	return r.Class[strings.LastIndex(r.Class, ".")+1:]
+}
+
+// Status returns the state of the job as shown by its ball color, e.g. "blue", "red" or "disabled"
+func (r JobRef) Status() string {
+	return strings.TrimSuffix(r.Color, "_anime")
+}
+
+type JobListOptions struct {
+	Folder    string
+	Recursive bool
+	Type      string
+	Status    string
+	Label     string
+	Tree      bool
+	Output    string
+}
+
+// ListJobs outputs the jobs and folders below opts.Folder that match the filters in opts
+func ListJobs(c *APIClient, opts JobListOptions) error {
+	items, err := walkTree(c, jobFullName(opts.Folder), opts.Recursive)
+	if err != nil {
+		return err
+	}
+
+	var matched []JobRef
+	for _, item := range items {
+		if opts.Type != "" && item.Type() != opts.Type {
+			continue
+		}
+		if opts.Status != "" && item.Status() != opts.Status {
+			continue
+		}
+		matched = append(matched, item)
+	}
+
+	if opts.Label != "" {
+		matched, err = filterJobsByLabel(c, matched, opts.Label)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case opts.Output == "json":
+		jobsJSON, err := json.MarshalIndent(matched, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode job list as JSON: %s", err)
+		}
+		fmt.Printf("%s\n", jobsJSON)
+	case opts.Tree:
+		printJobTree(matched, jobFullName(opts.Folder))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tSTATUS\tLAST BUILD\tRESULT\tTIME")
+		for _, item := range matched {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.FullName, item.Type(), item.Status(), lastBuildColumns(item))
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+/*
+	Returns every job and folder below the specified folder, sorted by full name. An empty fullName refers to the
+	root of the Jenkins instance. If fullName is a job rather than a folder, the job itself is returned.
+	When recursive is set, folders are walked concurrently.
+*/
+func walkTree(c *APIClient, fullName string, recursive bool) ([]JobRef, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		items    []JobRef
+		firstErr error
+	)
+	sem := make(chan struct{}, walkConcurrency)
+
+	var walk func(folder string)
+	walk = func(folder string) {
+		defer wg.Done()
+
+		sem <- struct{}{}
+		var node JobRef
+		query := map[string]string{"tree": fmt.Sprintf("%s,jobs[%s]", jobTreeFields, jobTreeFields)}
+		_, err := c.Client.Requester.GetJSON(c.Context, jobBase(folder), &node, query)
+		<-sem
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unable to retrieve \"%s\": %s", folder, err)
+			}
+			return
+		}
+
+		if folder == fullName && folder != "" && !node.IsFolder() {
+			if node.FullName == "" {
+				firstErr = fmt.Errorf("job \"%s\" not found", folder)
+				return
+			}
+			node.Jobs = nil
+			items = append(items, node)
+			return
+		}
+
+		for _, child := range node.Jobs {
+			items = append(items, child)
+			if recursive && child.IsFolder() {
+				wg.Add(1)
+				go walk(child.FullName)
+			}
+		}
+	}
+
+	wg.Add(1)
+	walk(fullName)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// compare path segments so that "a/b" sorts directly after "a" rather than after "a-b"
+	sort.Slice(items, func(i, j int) bool {
+		return strings.ReplaceAll(items[i].FullName, "/", "\x00") < strings.ReplaceAll(items[j].FullName, "/", "\x00")
+	})
+
+	return items, nil
+}
+
+/*
+	Returns every buildable job at or below the specified job or folder. An empty fullName walks the
+	entire instance. Folders are descended into but not included in the result.
+*/
+func walkJobs(c *APIClient, fullName string) ([]JobRef, error) {
+	items, err := walkTree(c, fullName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []JobRef
+	for _, item := range items {
+		if !item.IsFolder() {
+			jobs = append(jobs, item)
+		}
+	}
+
+	return jobs, nil
+}
+
+// Returns the jobs whose label expression refers to label. Folders never match.
+func filterJobsByLabel(c *APIClient, jobs []JobRef, label string) ([]JobRef, error) {
+	configs, err := getJobConfigs(c, jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []JobRef
+	for _, j := range jobs {
+		for _, l := range jobLabels(configs[j.FullName]) {
+			if l == label {
+				matched = append(matched, j)
+				break
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+var (
+	assignedNodeRegex  = regexp.MustCompile(`<assignedNode>([^<]*)</assignedNode>`)
+	pipelineLabelRegex = regexp.MustCompile(`(?:label\s*\(?|node\s*\(\s*(?:label\s*:\s*)?)\s*['"]([^'"]+)['"]`)
+	labelTokenRegex    = regexp.MustCompile(`[^\s&|!()]+`)
+)
+
+/*
+	Returns the labels referred to by a job's config.xml: the label expression of freestyle jobs, or the
+	arguments of "label" and "node" steps found in an inline pipeline script.
+*/
+func jobLabels(config string) []string {
+	// pipeline scripts are stored XML-escaped, e.g. node(&apos;rhel7&apos;)
+	config = html.UnescapeString(config)
+
+	var expressions []string
+	for _, m := range assignedNodeRegex.FindAllStringSubmatch(config, -1) {
+		expressions = append(expressions, m[1])
+	}
+	for _, m := range pipelineLabelRegex.FindAllStringSubmatch(config, -1) {
+		expressions = append(expressions, m[1])
+	}
+
+	var labels []string
+	for _, e := range expressions {
+		labels = append(labels, labelTokenRegex.FindAllString(e, -1)...)
+	}
+
+	return labels
+}
+
+// Returns the config.xml of each job, keyed by full name, fetching them concurrently. Folders are skipped.
+func getJobConfigs(c *APIClient, jobs []JobRef) (map[string]string, error) {
+	var nonFolders []JobRef
+	for _, j := range jobs {
+		if !j.IsFolder() {
+			nonFolders = append(nonFolders, j)
+		}
+	}
+	return getItemConfigs(c, nonFolders)
+}
+
+// Returns the config.xml of each job or folder, keyed by full name, fetching them concurrently
+func getItemConfigs(c *APIClient, items []JobRef) (map[string]string, error) {
+	configs, errs := fetchItemConfigs(c, items)
+	for _, j := range items {
+		if err, ok := errs[j.FullName]; ok {
+			return configs, err
+		}
+	}
+	return configs, nil
+}
+
+// Returns the config.xml of each job or folder, and the error of each one that could not be retrieved
+func fetchItemConfigs(c *APIClient, items []JobRef) (map[string]string, map[string]error) {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	configs := make(map[string]string)
+	errs := make(map[string]error)
+	sem := make(chan struct{}, walkConcurrency)
+
+	for _, j := range items {
+		wg.Add(1)
+		go func(fullName string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			config, err := getJobConfig(c, fullName)
+			<-sem
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[fullName] = err
+				return
+			}
+			configs[fullName] = config
+		}(j.FullName)
+	}
+	wg.Wait()
+
+	return configs, errs
+}
+
+func getJobConfig(c *APIClient, fullName string) (string, error) {
+	var config string
+	resp, err := c.Client.Requester.GetXML(c.Context, jobBase(fullName)+"/config.xml", &config, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve config.xml of \"%s\": %s", fullName, err)
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("unable to retrieve config.xml of \"%s\": %s", fullName, resp.Status)
+	}
+
+	return config, nil
+}
+
+func lastBuildColumns(item JobRef) string {
+	if item.LastBuild == nil {
+		return "-\t-\t-"
+	}
+
+	result := item.LastBuild.Result
+	if result == "" {
+		result = "RUNNING"
+	}
+	ts := time.Unix(0, item.LastBuild.Timestamp*int64(time.Millisecond))
+
+	return fmt.Sprintf("#%d\t%s\t%s", item.LastBuild.Number, result, ts.Format("2006-01-02 15:04"))
+}
+
+// Prints items as an indented tree relative to root. Parent folders filtered out of items are still shown.
+func printJobTree(items []JobRef, root string) {
+	printed := make(map[string]bool)
+	for _, item := range items {
+		rel := strings.TrimPrefix(strings.TrimPrefix(item.FullName, root), "/")
+		segments := strings.Split(rel, "/")
+
+		for depth := range segments[:len(segments)-1] {
+			parent := strings.Join(segments[:depth+1], "/")
+			if !printed[parent] {
+				fmt.Printf("%s%s/\n", strings.Repeat("  ", depth), segments[depth])
+				printed[parent] = true
+			}
+		}
+
+		name := segments[len(segments)-1]
+		if item.IsFolder() {
+			if printed[rel] {
+				continue
+			}
+			printed[rel] = true
+			fmt.Printf("%s%s/\n", strings.Repeat("  ", len(segments)-1), name)
+			continue
+		}
+
+		status := "never built"
+		if item.LastBuild != nil {
+			status = strings.ReplaceAll(lastBuildColumns(item), "\t", " ")
+		}
+		fmt.Printf("%s%s [%s] %s\n", strings.Repeat("  ", len(segments)-1), name, item.Status(), status)
+	}
+}
+
+/*
+	Returns the full name of a job (e.g. "ai-foundation/abp-code-scan/ghenkins") given a job URL in the
+	format accepted by parseJobURL.
+*/
+func jobFullName(jobURL string) string {
+	name, parents, _ := parseJobURL(jobURL)
+	return strings.Join(append(parents, name), "/")
+}
+
+/*
+	Returns the API path of a job given its full name. For example "a/b" returns "/job/a/job/b".
+	An empty name refers to the root of the Jenkins instance.
+*/
+func jobBase(fullName string) string {
+	if fullName == "" {
+		return "/"
+	}
+
+	var segments []string
+	for _, s := range strings.Split(fullName, "/") {
+		segments = append(segments, url.PathEscape(s))
+	}
+
+	return "/job/" + strings.Join(segments, "/job/")
+}
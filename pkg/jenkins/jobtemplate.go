@@ -0,0 +1,234 @@
+package jenkins
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+type JobTemplateOptions struct {
+	Template string
+	// YAML files of values, each describing one job or, under a "jobs" key, several jobs sharing "defaults"
+	Values []string
+	// Apply the changes instead of only showing the plan
+	Yes bool
+}
+
+// jobValues are the values a job template is rendered with. The "name" value is the full name of the job.
+type jobValues struct {
+	Source string
+	Name   string
+	Values map[string]interface{}
+}
+
+var jobTemplateFuncs = template.FuncMap{
+	"xml": func(v interface{}) (string, error) {
+		if v == nil {
+			return "", fmt.Errorf("xml: the value is not set")
+		}
+		return escapeXMLText(fmt.Sprint(v)), nil
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"default": func(def interface{}, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"required": func(name string, v interface{}) (interface{}, error) {
+		if v == nil || v == "" {
+			return nil, fmt.Errorf("value \"%s\" is required", name)
+		}
+		return v, nil
+	},
+	"join": func(sep string, v []interface{}) string {
+		s := make([]string, len(v))
+		for i, e := range v {
+			s[i] = fmt.Sprint(e)
+		}
+		return strings.Join(s, sep)
+	},
+}
+
+// RenderJobTemplate outputs the config.xml rendered from a template for each job in the values files
+func RenderJobTemplate(opts JobTemplateOptions) error {
+	jobs, configs, err := renderJobTemplate(opts)
+	if err != nil {
+		return err
+	}
+
+	for i, j := range jobs {
+		if len(jobs) > 1 {
+			fmt.Printf("==> %s <==\n", j.Name)
+		}
+		fmt.Print(configs[i])
+	}
+
+	return nil
+}
+
+/*
+	Renders a template for each job in the values files and shows the difference with the current config of
+	each job, followed by a summary of the jobs to create and update. With opts.Yes, the changes are applied.
+*/
+func ApplyJobTemplate(c *APIClient, opts JobTemplateOptions) error {
+	jobs, configs, err := renderJobTemplate(opts)
+	if err != nil {
+		return err
+	}
+
+	var creates, updates []int
+	for i, j := range jobs {
+		if j.Name == "" {
+			return fmt.Errorf("%s: a \"name\" value with the full name of the job is required", j.Source)
+		}
+
+		changed, exists, err := previewJobConfig(c, j.Name, configs[i], j.Name+" (rendered)")
+		if err != nil {
+			return err
+		}
+		switch {
+		case !exists:
+			creates = append(creates, i)
+		case changed:
+			updates = append(updates, i)
+		}
+	}
+
+	fmt.Printf("\nPlan: %d to create, %d to update, %d unchanged\n", len(creates), len(updates), len(jobs)-len(creates)-len(updates))
+	if len(creates)+len(updates) == 0 {
+		return nil
+	}
+	if !opts.Yes {
+		log.Printf("Run again with --yes to apply these changes")
+		return nil
+	}
+
+	failed := 0
+	for _, i := range creates {
+		if err := createJob(c, jobs[i].Name, configs[i]); err != nil {
+			log.Printf("%s", err)
+			failed++
+			continue
+		}
+		log.Printf("Created %s", jobs[i].Name)
+	}
+	for _, i := range updates {
+		if err := updateJobConfig(c, jobs[i].Name, configs[i]); err != nil {
+			log.Printf("%s", err)
+			failed++
+			continue
+		}
+		log.Printf("Updated %s", jobs[i].Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d jobs failed", failed, len(creates)+len(updates))
+	}
+
+	return nil
+}
+
+// Returns the jobs described by the values files along with their rendered, well-formed configs
+func renderJobTemplate(opts JobTemplateOptions) ([]jobValues, []string, error) {
+	content, err := ioutil.ReadFile(opts.Template)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read template: %s", err)
+	}
+	tmpl, err := template.New(filepath.Base(opts.Template)).Option("missingkey=error").Funcs(jobTemplateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var jobs []jobValues
+	for _, file := range opts.Values {
+		j, err := loadJobValues(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		jobs = append(jobs, j...)
+	}
+	if len(jobs) == 0 {
+		return nil, nil, fmt.Errorf("no jobs found in the values files")
+	}
+
+	configs := make([]string, len(jobs))
+	for i, j := range jobs {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, j.Values); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", j.Source, err)
+		}
+		configs[i] = buf.String()
+		if _, err := parseXML(configs[i]); err != nil {
+			return nil, nil, fmt.Errorf("%s: rendered config: %s", j.Source, err)
+		}
+	}
+
+	return jobs, configs, nil
+}
+
+/*
+	Reads a values file. A file with a "jobs" list describes one job per entry, each merged over the optional
+	"defaults" map; any other file describes a single job.
+*/
+func loadJobValues(file string) ([]jobValues, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read values: %s", err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	entries, ok := doc["jobs"].([]interface{})
+	if !ok {
+		return []jobValues{newJobValues(file, doc)}, nil
+	}
+
+	defaults, _ := doc["defaults"].(map[string]interface{})
+	var jobs []jobValues
+	for i, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: jobs[%d] is not a map", file, i)
+		}
+		jobs = append(jobs, newJobValues(fmt.Sprintf("%s: jobs[%d]", file, i), mergeValues(defaults, entry)))
+	}
+
+	return jobs, nil
+}
+
+func newJobValues(source string, values map[string]interface{}) jobValues {
+	name, _ := values["name"].(string)
+	if name != "" {
+		source = fmt.Sprintf("%s (%s)", source, name)
+	}
+	return jobValues{Source: source, Name: jobFullName(name), Values: values}
+}
+
+// Returns the values of base overridden by those of override, merging nested maps
+func mergeValues(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		b, baseIsMap := merged[k].(map[string]interface{})
+		o, overrideIsMap := v.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[k] = mergeValues(b, o)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
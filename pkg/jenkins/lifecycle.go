@@ -0,0 +1,147 @@
+package jenkins
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CreateJob creates a job from the config.xml in file ("-" for stdin)
+func CreateJob(c *APIClient, jobURL string, file string) error {
+	config, err := readConfigFile(file)
+	if err != nil {
+		return err
+	}
+	if _, err := parseXML(config); err != nil {
+		return fmt.Errorf("%s: %s", file, err)
+	}
+
+	fullName := jobFullName(jobURL)
+	if err := createJob(c, fullName, config); err != nil {
+		return err
+	}
+
+	log.Printf("Created %s", fullName)
+
+	return nil
+}
+
+// CopyJob creates a job as a copy of an existing one. The copy may be placed in a different folder.
+func CopyJob(c *APIClient, fromURL string, toURL string) error {
+	from := jobFullName(fromURL)
+	to := jobFullName(toURL)
+	parent, name := splitFullName(to)
+
+	endpoint := "/createItem"
+	if parent != "" {
+		endpoint = jobBase(parent) + "/createItem"
+	}
+
+	// "from" is resolved relative to the destination folder unless it is absolute
+	query := map[string]string{
+		"name": name,
+		"mode": "copy",
+		"from": "/" + from,
+	}
+	resp, err := c.Client.Requester.Post(c.Context, endpoint, bytes.NewBufferString(""), nil, query)
+	if err != nil {
+		return fmt.Errorf("failed to copy \"%s\" to \"%s\": %s", from, to, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to copy \"%s\" to \"%s\": %s", from, to, resp.Status)
+	}
+
+	log.Printf("Copied %s to %s", from, to)
+
+	return nil
+}
+
+// RenameJob changes the name of a job without moving it to another folder
+func RenameJob(c *APIClient, jobURL string, newName string) error {
+	fullName := jobFullName(jobURL)
+	if strings.Contains(newName, "/") {
+		return fmt.Errorf("invalid name \"%s\": use move to change the folder of a job", newName)
+	}
+
+	form := url.Values{}
+	form.Set("newName", newName)
+	if err := postForm(c, jobBase(fullName)+"/confirmRename", form); err != nil {
+		return fmt.Errorf("failed to rename \"%s\": %s", fullName, err)
+	}
+
+	log.Printf("Renamed %s to %s", fullName, newName)
+
+	return nil
+}
+
+// MoveJobs moves jobs into a folder. An empty folder refers to the root of the Jenkins instance.
+func MoveJobs(c *APIClient, jobURLs []string, folderURL string) error {
+	folder := jobFullName(folderURL)
+
+	return forEachJob(jobURLs, func(fullName string) error {
+		form := url.Values{}
+		form.Set("destination", "/"+folder)
+		if err := postForm(c, jobBase(fullName)+"/move/move", form); err != nil {
+			return fmt.Errorf("failed to move \"%s\" to \"%s\": %s", fullName, folder, err)
+		}
+		log.Printf("Moved %s to /%s", fullName, folder)
+		return nil
+	})
+}
+
+// SetJobsEnabled enables or disables jobs
+func SetJobsEnabled(c *APIClient, jobURLs []string, enabled bool) error {
+	action, done := "disable", "Disabled"
+	if enabled {
+		action, done = "enable", "Enabled"
+	}
+
+	return forEachJob(jobURLs, func(fullName string) error {
+		if err := postForm(c, jobBase(fullName)+"/"+action, nil); err != nil {
+			return fmt.Errorf("failed to %s \"%s\": %s", action, fullName, err)
+		}
+		log.Printf("%s %s", done, fullName)
+		return nil
+	})
+}
+
+// DeleteJobs deletes jobs, asking for confirmation first unless yes is set
+func DeleteJobs(c *APIClient, jobURLs []string, yes bool) error {
+	if !yes {
+		names := make([]string, len(jobURLs))
+		for i, j := range jobURLs {
+			names[i] = jobFullName(j)
+		}
+		if !confirm(fmt.Sprintf("Delete %s and all of their builds?", strings.Join(names, ", "))) {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	return forEachJob(jobURLs, func(fullName string) error {
+		if err := postForm(c, jobBase(fullName)+"/doDelete", nil); err != nil {
+			return fmt.Errorf("failed to delete \"%s\": %s", fullName, err)
+		}
+		log.Printf("Deleted %s", fullName)
+		return nil
+	})
+}
+
+// Calls fn for each job, continuing past failures so that one bad entry does not stop a bulk operation
+func forEachJob(jobURLs []string, fn func(fullName string) error) error {
+	failed := 0
+	for _, j := range jobURLs {
+		if err := fn(jobFullName(j)); err != nil {
+			log.Printf("%s", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d jobs failed", failed, len(jobURLs))
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+type LintOptions struct {
+	Folder string
+	Output string
+	Config LintConfig
+}
+
+// LintConfig overrides the default settings of lint rules, keyed by rule ID
+type LintConfig struct {
+	Rules map[string]LintRuleConfig
+}
+
+type LintRuleConfig struct {
+	Enabled *bool
+	// "error", "warning" or "note"
+	Level string
+	// Additional regular expressions matching secrets, for the hardcoded-secret rule
+	Patterns []string
+	// Labels of the built-in node, for the built-in-node rule
+	Labels []string
+}
+
+type LintFinding struct {
+	Job     string `json:"job"`
+	URL     string `json:"url"`
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type lintRule struct {
+	ID          string
+	Description string
+	Level       string
+	check       func(j lintJob, cfg LintRuleConfig) []string
+}
+
+// lintJob is the parsed config of a job, along with its inline pipeline script if it has one
+type lintJob struct {
+	JobRef
+	root   *xmlNode
+	script string
+	// The pipeline script is read from SCM and cannot be inspected
+	fromSCM bool
+}
+
+var lintRules = []lintRule{
+	{"missing-build-discarder", "Jobs should discard old builds", "warning", lintBuildDiscarder},
+	{"built-in-node", "Jobs should not run on the built-in node", "warning", lintBuiltInNode},
+	{"no-timeout", "Jobs should have a build timeout", "warning", lintTimeout},
+	{"concurrent-builds", "Jobs should not allow concurrent builds", "note", lintConcurrentBuilds},
+	{"scm-poll-every-minute", "Jobs should not poll SCM every minute", "warning", lintSCMPolling},
+	{"hardcoded-secret", "Shell steps should not contain hardcoded secrets", "error", lintSecrets},
+}
+
+var (
+	defaultSecretPatterns = []string{
+		`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)\s*[=:]\s*['"]?[^\s'"$%{]{6,}`,
+		`(?i)authorization:\s*(?:bearer|basic)\s+[a-z0-9._~+/=-]{8,}`,
+		`(?i)\s(?:-u|--user)\s+['"]?[^\s:'"$%]+:[^\s'"$%]{4,}`,
+		`\bAKIA[0-9A-Z]{16}\b`,
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+	}
+	pollSCMRegex = regexp.MustCompile(`pollSCM\s*\(\s*['"]([^'"]+)['"]`)
+)
+
+/*
+	Checks the config.xml of every job in a folder, recursively, against the lint rules and outputs the
+	findings as text, JSON or SARIF. Jobs whose config cannot be read are skipped and reported on stderr.
+	Returns an error if any finding has the "error" level.
+*/
+func LintJobs(c *APIClient, opts LintOptions) error {
+	if err := validateLintConfig(opts.Config); err != nil {
+		return err
+	}
+
+	jobs, err := walkJobs(c, jobFullName(opts.Folder))
+	if err != nil {
+		return err
+	}
+	configs, errs := fetchItemConfigs(c, jobs)
+
+	var findings []LintFinding
+	skipped := 0
+	for _, ref := range jobs {
+		if err, ok := errs[ref.FullName]; ok {
+			log.Printf("Skipping %s: %s", ref.FullName, err)
+			skipped++
+			continue
+		}
+		j, err := newLintJob(ref, configs[ref.FullName])
+		if err != nil {
+			log.Printf("Skipping %s: %s", ref.FullName, err)
+			skipped++
+			continue
+		}
+
+		for _, rule := range lintRules {
+			cfg := opts.Config.Rules[rule.ID]
+			if cfg.Enabled != nil && !*cfg.Enabled {
+				continue
+			}
+			level := rule.Level
+			if cfg.Level != "" {
+				level = cfg.Level
+			}
+
+			for _, msg := range rule.check(j, cfg) {
+				findings = append(findings, LintFinding{Job: j.FullName, URL: j.URL, Rule: rule.ID, Level: level, Message: msg})
+			}
+		}
+	}
+
+	switch opts.Output {
+	case "text":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "JOB\tRULE\tLEVEL\tMESSAGE")
+		for _, f := range findings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Job, f.Rule, f.Level, f.Message)
+		}
+		w.Flush()
+		fmt.Printf("\n%d findings in %d jobs", len(findings), len(jobs)-skipped)
+		if skipped > 0 {
+			fmt.Printf(" (%d jobs could not be read)", skipped)
+		}
+		fmt.Println()
+	case "json":
+		if findings == nil {
+			findings = []LintFinding{}
+		}
+		out, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "sarif":
+		out, err := json.MarshalIndent(newSARIFLog(findings, opts.Config), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	default:
+		return fmt.Errorf("invalid output format \"%s\": expected text, json or sarif", opts.Output)
+	}
+
+	errors := 0
+	for _, f := range findings {
+		if f.Level == "error" {
+			errors++
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("%d findings with level \"error\"", errors)
+	}
+
+	return nil
+}
+
+// Checks the rule IDs, levels and patterns of a lint configuration
+func validateLintConfig(cfg LintConfig) error {
+	for id, r := range cfg.Rules {
+		if findLintRule(id) == nil {
+			return fmt.Errorf("unknown lint rule \"%s\"", id)
+		}
+		switch r.Level {
+		case "", "error", "warning", "note":
+		default:
+			return fmt.Errorf("invalid level \"%s\" for lint rule \"%s\": expected error, warning or note", r.Level, id)
+		}
+		for _, p := range r.Patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("invalid pattern \"%s\" for lint rule \"%s\": %s", p, id, err)
+			}
+		}
+	}
+	return nil
+}
+
+func findLintRule(id string) *lintRule {
+	for i := range lintRules {
+		if lintRules[i].ID == id {
+			return &lintRules[i]
+		}
+	}
+	return nil
+}
+
+func newLintJob(ref JobRef, config string) (lintJob, error) {
+	root, err := parseXML(config)
+	if err != nil {
+		return lintJob{}, err
+	}
+
+	j := lintJob{JobRef: ref, root: root}
+	if script := j.find("/flow-definition/definition/script"); len(script) > 0 {
+		j.script = script[0].Text
+	} else if j.isPipeline() {
+		// e.g. CpsScmFlowDefinition
+		j.fromSCM = true
+	}
+
+	return j, nil
+}
+
+func (j lintJob) find(expr string) []*xmlNode {
+	x, err := compileXPath(expr)
+	if err != nil {
+		log.Fatalf("invalid lint expression \"%s\": %s", expr, err)
+	}
+	return x.Select(j.root)
+}
+
+func (j lintJob) isPipeline() bool {
+	return j.root.Name == "flow-definition"
+}
+
+func lintBuildDiscarder(j lintJob, cfg LintRuleConfig) []string {
+	if len(j.find("//jenkins.model.BuildDiscarderProperty")) > 0 || len(j.find("/*/logRotator")) > 0 ||
+		strings.Contains(j.script, "buildDiscarder") {
+		return nil
+	}
+	return []string{"no build discarder is configured, so builds are kept forever"}
+}
+
+func lintBuiltInNode(j lintJob, cfg LintRuleConfig) []string {
+	builtIn := cfg.Labels
+	if len(builtIn) == 0 {
+		builtIn = []string{builtInNodeName, "master"}
+	}
+
+	var msgs []string
+	for _, label := range jobLabels(j.root.String()) {
+		for _, b := range builtIn {
+			if label == b {
+				msgs = append(msgs, fmt.Sprintf("runs on the built-in node (label \"%s\")", label))
+			}
+		}
+	}
+
+	// freestyle jobs that cannot roam and have no label expression are tied to the built-in node
+	if roam := j.find("/*/canRoam"); len(roam) > 0 && strings.TrimSpace(roam[0].Text) == "false" && len(j.find("/*/assignedNode")) == 0 {
+		msgs = append(msgs, "runs on the built-in node (no label expression and roaming disabled)")
+	}
+
+	return msgs
+}
+
+func lintTimeout(j lintJob, cfg LintRuleConfig) []string {
+	// the timeout of a pipeline read from SCM is set in its Jenkinsfile
+	if j.fromSCM {
+		return nil
+	}
+	if len(j.find("//hudson.plugins.build__timeout.BuildTimeoutWrapper")) > 0 || strings.Contains(j.script, "timeout(") {
+		return nil
+	}
+	return []string{"no build timeout is configured"}
+}
+
+func lintConcurrentBuilds(j lintJob, cfg LintRuleConfig) []string {
+	if j.isPipeline() {
+		if len(j.find("//org.jenkinsci.plugins.workflow.job.properties.DisableConcurrentBuildsJobProperty")) > 0 ||
+			strings.Contains(j.script, "disableConcurrentBuilds") {
+			return nil
+		}
+		return []string{"concurrent builds are allowed"}
+	}
+
+	if c := j.find("/*/concurrentBuild"); len(c) > 0 && strings.TrimSpace(c[0].Text) == "true" {
+		return []string{"concurrent builds are allowed"}
+	}
+	return nil
+}
+
+func lintSCMPolling(j lintJob, cfg LintRuleConfig) []string {
+	var specs []string
+	for _, n := range j.find("//hudson.triggers.SCMTrigger/spec") {
+		specs = append(specs, n.Text)
+	}
+	for _, m := range pollSCMRegex.FindAllStringSubmatch(j.script, -1) {
+		specs = append(specs, m[1])
+	}
+
+	var msgs []string
+	for _, spec := range specs {
+		for _, line := range strings.Split(spec, "\n") {
+			line = strings.TrimSpace(line)
+			fields := strings.Fields(line)
+			if len(fields) < 5 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			switch fields[0] {
+			case "*", "*/1", "H/1":
+				msgs = append(msgs, fmt.Sprintf("polls SCM every minute (\"%s\")", line))
+			}
+		}
+	}
+
+	return msgs
+}
+
+// Secrets are never included in the findings, only their location
+func lintSecrets(j lintJob, cfg LintRuleConfig) []string {
+	var patterns []*regexp.Regexp
+	for _, p := range defaultSecretPatterns {
+		patterns = append(patterns, regexp.MustCompile(p))
+	}
+	// custom patterns were validated by validateLintConfig
+	for _, p := range cfg.Patterns {
+		patterns = append(patterns, regexp.MustCompile(p))
+	}
+
+	steps := j.find("//hudson.tasks.Shell/command")
+	steps = append(steps, j.find("//hudson.tasks.BatchFile/command")...)
+	steps = append(steps, j.find("/flow-definition/definition/script")...)
+
+	var msgs []string
+	for _, step := range steps {
+		for i, line := range strings.Split(step.Text, "\n") {
+			for _, re := range patterns {
+				if re.MatchString(line) {
+					msgs = append(msgs, fmt.Sprintf("possible hardcoded secret on line %d of %s", i+1, step.Path()))
+					break
+				}
+			}
+		}
+	}
+
+	return msgs
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name  string      `json:"name"`
+			Rules []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	DefaultConfiguration map[string]string `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+	LogicalLocations []map[string]string `json:"logicalLocations"`
+}
+
+// Returns the findings as a SARIF 2.1.0 log, locating each one in the config.xml of its job
+func newSARIFLog(findings []LintFinding, cfg LintConfig) sarifLog {
+	var run sarifRun
+	run.Tool.Driver.Name = "tronci"
+	for _, r := range lintRules {
+		level := r.Level
+		if l := cfg.Rules[r.ID].Level; l != "" {
+			level = l
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{r.Description},
+			DefaultConfiguration: map[string]string{"level": level},
+		})
+	}
+
+	run.Results = []sarifResult{}
+	for _, f := range findings {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = f.Job + "/config.xml"
+		loc.LogicalLocations = []map[string]string{{"fullyQualifiedName": f.Job, "kind": "job"}}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     f.Level,
+			Message:   sarifMessage{f.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	return sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}
+}
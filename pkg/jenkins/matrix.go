@@ -0,0 +1,275 @@
+package jenkins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type MatrixOptions struct {
+	// YAML file of axes, each a parameter name with the list of its values
+	Axes string
+	// Parameter values as KEY=VALUE, common to every combination
+	Params []string
+	// Maximum number of builds tracked at once
+	MaxConcurrent int
+	// Wait for every build to complete instead of only for it to start
+	Wait bool
+}
+
+// Outcome of triggering one combination of the matrix
+type matrixRun struct {
+	Params   map[string]string
+	Number   int64
+	URL      string
+	Result   string
+	Duration time.Duration
+	Err      error
+}
+
+/*
+	Triggers a build of a job for every combination of the parameter values in the axes file, tracking at most
+	opts.MaxConcurrent builds at once. A build is tracked until it starts or, with opts.Wait, until it completes.
+	Every combination is validated against the job's parameter definitions before any build is triggered.
+	Prints a table of the builds with their result, duration and URL.
+*/
+func TriggerBuildMatrix(c *APIClient, jobURL string, opts MatrixOptions) error {
+	fullName := jobFullName(jobURL)
+
+	common, err := parseParameters(opts.Params)
+	if err != nil {
+		return err
+	}
+	axes, excludes, err := loadMatrixAxes(opts.Axes)
+	if err != nil {
+		return err
+	}
+	combinations := expandMatrix(axes, excludes, common)
+	if len(combinations) == 0 {
+		return fmt.Errorf("%s: no combinations to build", opts.Axes)
+	}
+
+	defs, err := getJobParameters(c, fullName)
+	if err != nil {
+		return err
+	}
+	for _, params := range combinations {
+		if err := validateParameters(fullName, defs, params); err != nil {
+			return err
+		}
+	}
+
+	concurrency := opts.MaxConcurrent
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	log.Printf("Triggering %d builds of %s, at most %d at a time", len(combinations), fullName, concurrency)
+
+	runs := make([]matrixRun, len(combinations))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				runs[i] = runMatrixCombination(c, fullName, len(defs) > 0, combinations[i], opts.Wait)
+			}
+		}()
+	}
+	for i := range combinations {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\nPARAMETERS\tBUILD\tRESULT\tDURATION\tURL")
+	for _, r := range runs {
+		build, duration := "-", "-"
+		if r.Number > 0 {
+			build = fmt.Sprintf("#%d", r.Number)
+		}
+		if r.Duration > 0 {
+			duration = r.Duration.String()
+		}
+		result := r.Result
+		if r.Err != nil {
+			result = "ERROR: " + r.Err.Error()
+		}
+		if r.Err != nil || (opts.Wait && r.Result != "SUCCESS") {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", matrixLabel(r.Params, axes), build, result, duration, r.URL)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d builds failed", failed, len(runs))
+	}
+
+	return nil
+}
+
+// Triggers one build and tracks it until it starts or, if wait is set, completes
+func runMatrixCombination(c *APIClient, fullName string, parameterized bool, params map[string]string, wait bool) matrixRun {
+	run := matrixRun{Params: params}
+
+	queueID, err := triggerBuild(c, fullName, parameterized, params)
+	if err != nil {
+		run.Err = err
+		return run
+	}
+	if run.Number, err = waitForQueueItem(c, queueID); err != nil {
+		run.Err = err
+		return run
+	}
+	log.Printf("Started %s #%d (%s)", fullName, run.Number, matrixLabel(params, nil))
+
+	if !wait {
+		run.Result = "STARTED"
+		run.URL = fmt.Sprintf("%s%s/%d/", strings.TrimSuffix(c.Client.Server, "/"), jobBase(fullName), run.Number)
+		return run
+	}
+
+	// an unsuccessful result is reported in the table rather than as an error
+	status, err := followBuild(c, fullName, run.Number, false)
+	if err != nil && status.Result == "" {
+		run.Err = err
+	}
+	run.Result, run.URL = status.Result, status.URL
+	run.Duration = time.Duration(status.Duration) * time.Millisecond
+
+	return run
+}
+
+/*
+	Reads an axes file: a map of parameter names to lists of values, e.g. {OS: [linux, windows], JDK: [11, 17]}.
+	A file with an "axes" key may also list combinations to leave out under "exclude", each a map of some of
+	the axes to a value.
+*/
+func loadMatrixAxes(file string) (map[string][]string, []map[string]string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read axes: %s", err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	axesDoc := doc
+	var excludes []map[string]string
+	if a, ok := doc["axes"].(map[string]interface{}); ok {
+		axesDoc = a
+		list, _ := doc["exclude"].([]interface{})
+		for i, e := range list {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("%s: exclude[%d] is not a map", file, i)
+			}
+			exclude := make(map[string]string)
+			for k, v := range m {
+				exclude[k] = fmt.Sprint(v)
+			}
+			excludes = append(excludes, exclude)
+		}
+	}
+
+	axes := make(map[string][]string)
+	for name, v := range axesDoc {
+		values, ok := v.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, nil, fmt.Errorf("%s: axis \"%s\" is not a list of values", file, name)
+		}
+		for _, value := range values {
+			axes[name] = append(axes[name], fmt.Sprint(value))
+		}
+	}
+	if len(axes) == 0 {
+		return nil, nil, fmt.Errorf("%s: no axes defined", file)
+	}
+
+	return axes, excludes, nil
+}
+
+// Returns every combination of the axis values that matches none of the excludes, each merged over common
+func expandMatrix(axes map[string][]string, excludes []map[string]string, common map[string]string) []map[string]string {
+	var names []string
+	for name := range axes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	combinations := []map[string]string{{}}
+	for _, name := range names {
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, value := range axes[name] {
+				params := map[string]string{name: value}
+				for k, v := range combination {
+					params[k] = v
+				}
+				next = append(next, params)
+			}
+		}
+		combinations = next
+	}
+
+	var result []map[string]string
+	for _, params := range combinations {
+		if matchesAnyExclude(params, excludes) {
+			continue
+		}
+		for k, v := range common {
+			if _, ok := params[k]; !ok {
+				params[k] = v
+			}
+		}
+		result = append(result, params)
+	}
+
+	return result
+}
+
+func matchesAnyExclude(params map[string]string, excludes []map[string]string) bool {
+	for _, exclude := range excludes {
+		matches := true
+		for k, v := range exclude {
+			if params[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the values of the axes of a combination as "K1=V1 K2=V2", or of every parameter if axes is nil
+func matrixLabel(params map[string]string, axes map[string][]string) string {
+	var keys []string
+	for k := range params {
+		if _, ok := axes[k]; ok || axes == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, k+"="+params[k])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,79 @@
+package jenkins
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	tests := []struct {
+		name     string
+		axes     map[string][]string
+		excludes []map[string]string
+		common   map[string]string
+		want     []map[string]string
+	}{
+		{
+			"single axis",
+			map[string][]string{"OS": {"linux", "windows"}},
+			nil, nil,
+			[]map[string]string{{"OS": "linux"}, {"OS": "windows"}},
+		},
+		{
+			"axes in alphabetical order",
+			map[string][]string{"OS": {"linux", "windows"}, "JDK": {"11", "17"}},
+			nil, nil,
+			[]map[string]string{
+				{"JDK": "11", "OS": "linux"}, {"JDK": "11", "OS": "windows"},
+				{"JDK": "17", "OS": "linux"}, {"JDK": "17", "OS": "windows"},
+			},
+		},
+		{
+			"excludes",
+			map[string][]string{"OS": {"linux", "windows"}, "JDK": {"11", "17"}},
+			[]map[string]string{{"OS": "windows", "JDK": "11"}, {"JDK": "8"}},
+			nil,
+			[]map[string]string{{"JDK": "11", "OS": "linux"}, {"JDK": "17", "OS": "linux"}, {"JDK": "17", "OS": "windows"}},
+		},
+		{
+			"partial exclude",
+			map[string][]string{"OS": {"linux", "windows"}, "JDK": {"11", "17"}},
+			[]map[string]string{{"OS": "windows"}},
+			nil,
+			[]map[string]string{{"JDK": "11", "OS": "linux"}, {"JDK": "17", "OS": "linux"}},
+		},
+		{
+			"common parameters",
+			map[string][]string{"OS": {"linux", "windows"}},
+			nil,
+			map[string]string{"BRANCH": "main", "OS": "mac"},
+			[]map[string]string{{"BRANCH": "main", "OS": "linux"}, {"BRANCH": "main", "OS": "windows"}},
+		},
+		{
+			"everything excluded",
+			map[string][]string{"OS": {"linux"}},
+			[]map[string]string{{"OS": "linux"}},
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandMatrix(tt.axes, tt.excludes, tt.common); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandMatrix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatrixLabel(t *testing.T) {
+	params := map[string]string{"OS": "linux", "JDK": "17", "BRANCH": "main"}
+
+	if got, want := matrixLabel(params, map[string][]string{"OS": nil, "JDK": nil}), "JDK=17 OS=linux"; got != want {
+		t.Errorf("matrixLabel() = %q, want %q", got, want)
+	}
+	if got, want := matrixLabel(params, nil), "BRANCH=main JDK=17 OS=linux"; got != want {
+		t.Errorf("matrixLabel() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,425 @@
+package jenkins
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type MigrateOptions struct {
+	Folder string
+	// Names of the two instances in the report, e.g. their contexts
+	FromName string
+	ToName   string
+	// Update items that exist on the target with a different config, instead of skipping them
+	Overwrite bool
+	DryRun    bool
+}
+
+// MigrationResult is the outcome of migrating a job or folder
+type MigrationResult struct {
+	Name   string
+	Action string // "created", "updated", "skipped" or "failed", prefixed with "would be" in a dry run
+	Reason string
+}
+
+var (
+	pluginAttrRegex    = regexp.MustCompile(`\splugin="([^"@]+)(?:@[^"]*)?"`)
+	scriptCredsRegex   = regexp.MustCompile(`credentials(?:Id)?\s*[:(]\s*['"]([^'"$]+)['"]`)
+	jobListSplitRegex  = regexp.MustCompile(`\s*,\s*`)
+	jobReferenceXPaths = []string{"//upstreamProjects", "//childProjects"}
+	// IDs of the credentials stored in a folder, in its config
+	folderCredentialsXPath = "//com.cloudbees.hudson.plugins.folder.properties.FolderCredentialsProvider_-FolderCredentialsProperty" +
+		"//java.util.concurrent.CopyOnWriteArrayList/*/id"
+)
+
+/*
+	Copies the jobs and folders in a folder from one jenkins instance to another. Folders are created before
+	their contents and jobs after the jobs they are triggered by. An item is skipped if a plugin it uses is not
+	installed on the target, if it refers to a credential ID the target does not have, if its parent folder
+	does not exist on the target and could not be created, or if it already exists on the target (unless
+	opts.Overwrite is set and its config differs). In a dry run, the credentials of the folders that would be
+	created or updated are read from their config. Outputs a report of each item and the reason it was skipped.
+*/
+func MigrateJobs(from *APIClient, to *APIClient, opts MigrateOptions) error {
+	folder := jobFullName(opts.Folder)
+
+	items, configs, err := getComparableItems(from, folder)
+	if err != nil {
+		return fmt.Errorf("%s: %s", opts.FromName, err)
+	}
+
+	plugins, err := getInstalledPlugins(to)
+	if err != nil {
+		return fmt.Errorf("%s: %s", opts.ToName, err)
+	}
+	creds := newCredentialIndex(to)
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "would be "
+	}
+
+	var results []MigrationResult
+	// folders that are not on the target, and whose contents cannot be migrated
+	notOnTarget := make(map[string]bool)
+	for _, name := range migrationOrder(items, configs) {
+		config := configs[name]
+		result := MigrationResult{Name: name}
+		skip := func(reason string) {
+			result.Action, result.Reason = prefix+"skipped", reason
+		}
+
+		parent, _ := splitFullName(name)
+		missingPlugins := missingPlugins(config, plugins)
+		missingCreds, err := creds.missing(parent, credentialReferences(config))
+		if err != nil {
+			return fmt.Errorf("%s: %s", opts.ToName, err)
+		}
+		exists, err := jobExists(to, name)
+		if err != nil {
+			return fmt.Errorf("%s: %s", opts.ToName, err)
+		}
+
+		switch {
+		case notOnTarget[parent]:
+			skip(fmt.Sprintf("parent folder %s was not created", parent))
+		case len(missingPlugins) > 0:
+			skip("missing plugins: " + strings.Join(missingPlugins, ", "))
+		case len(missingCreds) > 0:
+			skip("missing credentials: " + strings.Join(missingCreds, ", "))
+		case exists:
+			current, err := getJobConfig(to, name)
+			if err != nil {
+				return fmt.Errorf("%s: %s", opts.ToName, err)
+			}
+			currentConfig, _ := comparableConfig(current, nil)
+			desiredConfig, _ := comparableConfig(config, nil)
+			if currentConfig == desiredConfig {
+				// identical items do not prevent migrating their contents
+				result.Action, result.Reason = prefix+"skipped", "already exists with the same config"
+				break
+			}
+			if !opts.Overwrite {
+				skip("already exists with a different config (use --overwrite to update it)")
+				break
+			}
+			result.Action = prefix + "updated"
+			if !opts.DryRun {
+				if err := updateJobConfig(to, name, config); err != nil {
+					result.Action, result.Reason = "failed", err.Error()
+				}
+			}
+		default:
+			result.Action = prefix + "created"
+			if !opts.DryRun {
+				if err := createJob(to, name, config); err != nil {
+					result.Action, result.Reason = "failed", err.Error()
+				}
+			}
+		}
+
+		action := strings.TrimPrefix(result.Action, prefix)
+		if !exists && action != "created" {
+			notOnTarget[name] = true
+		}
+		// the folder is not created or updated in a dry run, so its credentials are not on the target yet
+		if opts.DryRun && items[name].IsFolder() && (action == "created" || action == "updated") {
+			creds.set(name, folderCredentials(config))
+		}
+
+		switch result.Action {
+		case "created", "updated":
+			log.Printf("%s %s", strings.Title(result.Action), name)
+		case "failed":
+			log.Printf("Failed to migrate %s: %s", name, result.Reason)
+		}
+		results = append(results, result)
+	}
+
+	counts := make(map[string]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\nITEM\tACTION\tREASON")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, r.Action, r.Reason)
+		counts[strings.TrimPrefix(r.Action, prefix)]++
+	}
+	w.Flush()
+
+	fmt.Printf("\n%s -> %s: %d %screated, %d %supdated, %d %sskipped, %d failed\n", opts.FromName, opts.ToName,
+		counts["created"], prefix, counts["updated"], prefix, counts["skipped"], prefix, counts["failed"])
+
+	if counts["failed"] > 0 {
+		return fmt.Errorf("%d of %d items failed to migrate", counts["failed"], len(results))
+	}
+
+	return nil
+}
+
+/*
+	Returns the names of the items in the order they must be created: folders before their contents, and
+	jobs after the jobs that trigger them or that they trigger. Reference cycles are broken arbitrarily.
+*/
+func migrationOrder(items map[string]JobRef, configs map[string]string) []string {
+	var names []string
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var order []string
+	visited := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		if parent, _ := splitFullName(name); parent != "" {
+			if _, ok := items[parent]; ok {
+				visit(parent)
+			}
+		}
+		for _, ref := range referencedJobs(name, configs[name], items) {
+			visit(ref)
+		}
+
+		order = append(order, name)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+
+	return order
+}
+
+// Returns the jobs in items that a job is triggered by or triggers, according to its config
+func referencedJobs(fullName string, config string, items map[string]JobRef) []string {
+	root, err := parseXML(config)
+	if err != nil {
+		return nil
+	}
+
+	var refs []string
+	for _, expr := range jobReferenceXPaths {
+		x, _ := compileXPath(expr)
+		for _, n := range x.Select(root) {
+			for _, ref := range jobListSplitRegex.Split(strings.TrimSpace(n.Text), -1) {
+				if name, ok := resolveJobName(ref, fullName, items); ok {
+					refs = append(refs, name)
+				}
+			}
+		}
+	}
+
+	return refs
+}
+
+/*
+	Resolves a job name as written in a job's config, relative to the job's folder or absolute, and reports
+	whether it refers to one of the items.
+*/
+func resolveJobName(ref string, fullName string, items map[string]JobRef) (string, bool) {
+	candidates := jobNameCandidates(ref, fullName)
+	if len(candidates) == 0 {
+		return "", false
+	}
+	for _, name := range candidates {
+		if _, ok := items[name]; ok {
+			return name, true
+		}
+	}
+
+	return candidates[0], false
+}
+
+/*
+	Returns the full names a job name written in a job's config may refer to, in the order jenkins tries them:
+	relative to the job's folder, then from the root, unless the name starts with "/", "./" or "../".
+*/
+func jobNameCandidates(ref string, fullName string) []string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(ref, "/") {
+		return []string{strings.TrimPrefix(path.Clean(ref), "/")}
+	}
+
+	parent, _ := splitFullName(fullName)
+	candidates := []string{strings.TrimPrefix(path.Clean("/"+parent+"/"+ref), "/")}
+	if !strings.HasPrefix(ref, ".") && candidates[0] != ref {
+		candidates = append(candidates, ref)
+	}
+	return candidates
+}
+
+// Returns the plugins that a config refers to in plugin attributes and that are not in installed
+func missingPlugins(config string, installed map[string]bool) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, m := range pluginAttrRegex.FindAllStringSubmatch(config, -1) {
+		if !installed[m[1]] && !seen[m[1]] {
+			missing = append(missing, m[1])
+		}
+		seen[m[1]] = true
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Returns the credential IDs a config refers to, in credentialsId elements or in pipeline scripts
+func credentialReferences(config string) []string {
+	root, err := parseXML(config)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	x, _ := compileXPath("//credentialsId")
+	for _, n := range x.Select(root) {
+		if id := strings.TrimSpace(n.Text); id != "" {
+			seen[id] = true
+		}
+	}
+	y, _ := compileXPath("//script")
+	for _, n := range y.Select(root) {
+		for _, m := range scriptCredsRegex.FindAllStringSubmatch(n.Text, -1) {
+			seen[m[1]] = true
+		}
+	}
+
+	var ids []string
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// Returns the IDs of the credentials stored in a folder config
+func folderCredentials(config string) []string {
+	root, err := parseXML(config)
+	if err != nil {
+		return nil
+	}
+
+	x, _ := compileXPath(folderCredentialsXPath)
+	var ids []string
+	for _, n := range x.Select(root) {
+		if id := strings.TrimSpace(n.Text); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func getInstalledPlugins(c *APIClient) (map[string]bool, error) {
+	plugins, err := c.Client.GetPlugins(c.Context, 1)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list plugins: %s", err)
+	}
+
+	installed := make(map[string]bool)
+	for _, p := range plugins.Raw.Plugins {
+		if p.Active {
+			installed[p.ShortName] = true
+		}
+	}
+
+	return installed, nil
+}
+
+/*
+	credentialIndex looks up the IDs of the credentials visible from a folder: those in the global domain of
+	the system store and of the folder stores of the folder and its parents. Stores are fetched once.
+*/
+type credentialIndex struct {
+	c      *APIClient
+	stores map[string]map[string]bool
+}
+
+func newCredentialIndex(c *APIClient) *credentialIndex {
+	return &credentialIndex{c: c, stores: make(map[string]map[string]bool)}
+}
+
+// Returns the IDs that are not visible from folder
+func (ci *credentialIndex) missing(folder string, ids []string) ([]string, error) {
+	var missing []string
+	for _, id := range ids {
+		found := false
+		for scope := folder; ; scope, _ = splitFullName(scope) {
+			store, err := ci.store(scope)
+			if err != nil {
+				return nil, err
+			}
+			if store[id] {
+				found = true
+				break
+			}
+			if scope == "" {
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// Sets the credential IDs of the store of a folder, e.g. of a folder that would be created in a dry run
+func (ci *credentialIndex) set(folder string, ids []string) {
+	store := make(map[string]bool)
+	for _, id := range ids {
+		store[id] = true
+	}
+	ci.stores[folder] = store
+}
+
+// Returns the credential IDs of the system store if folder is empty, or of the store of the folder
+func (ci *credentialIndex) store(folder string) (map[string]bool, error) {
+	if ids, ok := ci.stores[folder]; ok {
+		return ids, nil
+	}
+
+	endpoint := "/credentials/store/system/domain/_"
+	if folder != "" {
+		endpoint = jobBase(folder) + "/credentials/store/folder/domain/_"
+	}
+
+	var store struct {
+		Credentials []struct {
+			ID string `json:"id"`
+		} `json:"credentials"`
+	}
+	resp, err := ci.c.Client.Requester.GetJSON(ci.c.Context, endpoint, &store, map[string]string{"tree": "credentials[id]"})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list credentials of \"%s\": %s", folder, err)
+	}
+
+	ids := make(map[string]bool)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		for _, c := range store.Credentials {
+			ids[c.ID] = true
+		}
+	case http.StatusNotFound:
+		// the folder does not exist on the target yet, or has no credentials store
+	default:
+		return nil, fmt.Errorf("unable to list credentials of \"%s\": %s", folder, resp.Status)
+	}
+
+	ci.stores[folder] = ids
+
+	return ids, nil
+}
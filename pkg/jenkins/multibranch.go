@@ -0,0 +1,217 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Maximum time to wait for a requested branch indexing to start
+const indexingStartTimeout = 5 * time.Minute
+
+// Views of a multibranch project listing its branches and its pull requests
+var multibranchViews = map[string]string{
+	"branches": "default",
+	"prs":      "change-requests",
+}
+
+// A branch or pull request job of a multibranch project
+type branchJob struct {
+	JobRef
+	DisplayName string `json:"displayName"`
+	Buildable   bool   `json:"buildable"`
+}
+
+// Status of the branch indexing of a multibranch project
+type indexingStatus struct {
+	Result    string `json:"result"`
+	Building  bool   `json:"building"`
+	Timestamp int64  `json:"timestamp"`
+	Duration  int64  `json:"duration"`
+}
+
+/*
+	Triggers the branch indexing of a multibranch project, streams the indexing log until it completes, and
+	returns an error if the indexing is not successful.
+*/
+func ScanMultibranch(c *APIClient, projectURL string) error {
+	fullName := jobFullName(projectURL)
+	base := jobBase(fullName) + "/indexing"
+
+	if err := checkMultibranch(c, fullName); err != nil {
+		return err
+	}
+	before, err := getIndexingStatus(c, fullName)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Client.Requester.Post(c.Context, jobBase(fullName)+"/build", strings.NewReader(""), nil, map[string]string{"delay": "0"})
+	if err != nil {
+		return fmt.Errorf("failed to trigger indexing of %s: %s", fullName, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to trigger indexing of %s: %s", fullName, resp.Status)
+	}
+	log.Printf("Triggered indexing of %s", fullName)
+
+	// wait for the new indexing, which replaces the log of the previous one
+	deadline := time.Now().Add(indexingStartTimeout)
+	for {
+		status, err := getIndexingStatus(c, fullName)
+		if err != nil {
+			return err
+		}
+		if status.Building || status.Timestamp != before.Timestamp {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("indexing of %s did not start within %s", fullName, indexingStartTimeout)
+		}
+		time.Sleep(buildPollInterval)
+	}
+
+	var offset int64
+	for {
+		status, err := getIndexingStatus(c, fullName)
+		if err != nil {
+			return err
+		}
+
+		var more bool
+		if offset, more, err = streamConsole(c, base, offset); err != nil {
+			return fmt.Errorf("unable to retrieve the indexing log of %s: %s", fullName, err)
+		}
+
+		if !status.Building && !more {
+			log.Printf("Finished indexing %s: %s in %s", fullName, status.Result, time.Duration(status.Duration)*time.Millisecond)
+			if status.Result != "SUCCESS" {
+				return fmt.Errorf("indexing of %s finished with result %s", fullName, status.Result)
+			}
+			return nil
+		}
+		if status.Building {
+			time.Sleep(buildPollInterval)
+		}
+	}
+}
+
+// PrintIndexingLog outputs the log of the last branch indexing of a multibranch project
+func PrintIndexingLog(c *APIClient, projectURL string) error {
+	fullName := jobFullName(projectURL)
+	if err := checkMultibranch(c, fullName); err != nil {
+		return err
+	}
+
+	var text string
+	resp, err := c.Client.Requester.GetXML(c.Context, jobBase(fullName)+"/indexing/consoleText", &text, nil)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve the indexing log of %s: %s", fullName, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to retrieve the indexing log of %s: %s", fullName, resp.Status)
+	}
+
+	fmt.Print(text)
+
+	return nil
+}
+
+/*
+	Lists the branch jobs ("branches"), the pull request jobs ("prs") or the orphaned branch jobs ("orphans") of
+	a multibranch project with their last build. Orphaned branches are those whose branch no longer exists: they
+	are no longer buildable, and are deleted by the orphaned item strategy of the project.
+*/
+func ListBranchJobs(c *APIClient, projectURL string, kind string, output string) error {
+	fullName := jobFullName(projectURL)
+
+	endpoint := jobBase(fullName)
+	if view, ok := multibranchViews[kind]; ok {
+		endpoint += "/view/" + view
+	} else if kind != "orphans" {
+		return fmt.Errorf("invalid kind of branch jobs \"%s\": expected branches, prs or orphans", kind)
+	}
+	if err := checkMultibranch(c, fullName); err != nil {
+		return err
+	}
+
+	var resp struct {
+		Jobs []branchJob `json:"jobs"`
+	}
+	query := map[string]string{"tree": fmt.Sprintf("jobs[%s,displayName,buildable]", jobTreeFields)}
+	r, err := c.Client.Requester.GetJSON(c.Context, endpoint, &resp, query)
+	if err != nil {
+		return fmt.Errorf("unable to list the %s of %s: %s", kind, fullName, err)
+	}
+	// the view of pull requests only exists if the branch source discovers them
+	if r.StatusCode != http.StatusOK && !(r.StatusCode == http.StatusNotFound && kind == "prs") {
+		return fmt.Errorf("unable to list the %s of %s: %s", kind, fullName, r.Status)
+	}
+
+	jobs := []branchJob{}
+	for _, j := range resp.Jobs {
+		if kind != "orphans" || !j.Buildable {
+			jobs = append(jobs, j)
+		}
+	}
+
+	switch output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jobs)
+	case "text", "":
+	default:
+		return fmt.Errorf("invalid output format \"%s\": expected text or json", output)
+	}
+
+	if len(jobs) == 0 {
+		fmt.Printf("%s has no %s\n", fullName, map[string]string{"branches": "branches", "prs": "pull requests", "orphans": "orphaned branches"}[kind])
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDISPLAY NAME\tSTATUS\tLAST BUILD\tRESULT\tTIME")
+	for _, j := range jobs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", j.Name, j.DisplayName, j.Status(), lastBuildColumns(j.JobRef))
+	}
+	w.Flush()
+
+	return nil
+}
+
+// Returns an error unless the item is a multibranch project
+func checkMultibranch(c *APIClient, fullName string) error {
+	var project JobRef
+	resp, err := c.Client.Requester.GetJSON(c.Context, jobBase(fullName), &project, map[string]string{"tree": "_class"})
+	if err != nil {
+		return fmt.Errorf("unable to retrieve \"%s\": %s", fullName, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to retrieve \"%s\": %s", fullName, resp.Status)
+	}
+	if project.Type() != "multibranch" {
+		return fmt.Errorf("%s is a %s, not a multibranch project", fullName, project.Type())
+	}
+	return nil
+}
+
+// Returns the status of the last branch indexing of a multibranch project, which is empty if it was never indexed
+func getIndexingStatus(c *APIClient, fullName string) (indexingStatus, error) {
+	var status indexingStatus
+	query := map[string]string{"tree": "result,building,timestamp,duration"}
+	resp, err := c.Client.Requester.GetJSON(c.Context, jobBase(fullName)+"/indexing", &status, query)
+	if err != nil {
+		return status, fmt.Errorf("unable to retrieve the indexing status of %s: %s", fullName, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNotFound:
+		return status, nil
+	}
+	return status, fmt.Errorf("unable to retrieve the indexing status of %s: %s", fullName, resp.Status)
+}
@@ -0,0 +1,174 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ParameterDefinition is a build parameter declared by a job's ParametersDefinitionProperty
+type ParameterDefinition struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Choices     []string    `json:"choices,omitempty"`
+}
+
+// ListJobParameters outputs the parameter definitions of a job, as a table or as JSON
+func ListJobParameters(c *APIClient, jobURL string, output string) error {
+	fullName := jobFullName(jobURL)
+	defs, err := getJobParameters(c, fullName)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if defs == nil {
+			defs = []ParameterDefinition{}
+		}
+		return enc.Encode(defs)
+	case "text", "":
+	default:
+		return fmt.Errorf("invalid output format \"%s\": expected text or json", output)
+	}
+
+	if len(defs) == 0 {
+		fmt.Printf("%s has no parameters\n", fullName)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tDEFAULT\tCHOICES\tDESCRIPTION")
+	for _, d := range defs {
+		def := ""
+		if d.Default != nil {
+			def = fmt.Sprint(d.Default)
+		}
+		description := strings.Join(strings.Fields(d.Description), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Name, d.Type, snippet(def, nil), strings.Join(d.Choices, ","), snippet(description, nil))
+	}
+	w.Flush()
+
+	return nil
+}
+
+// Returns the parameters of a job. Types are short names, e.g. "choice" for a ChoiceParameterDefinition.
+func getJobParameters(c *APIClient, fullName string) ([]ParameterDefinition, error) {
+	var resp struct {
+		Property []struct {
+			ParameterDefinitions []struct {
+				Name                  string   `json:"name"`
+				Type                  string   `json:"type"`
+				Description           string   `json:"description"`
+				Choices               []string `json:"choices"`
+				DefaultParameterValue *struct {
+					Value interface{} `json:"value"`
+				} `json:"defaultParameterValue"`
+			} `json:"parameterDefinitions"`
+		} `json:"property"`
+	}
+	query := map[string]string{"tree": "property[parameterDefinitions[name,type,description,choices,defaultParameterValue[value]]]"}
+	r, err := c.Client.Requester.GetJSON(c.Context, jobBase(fullName), &resp, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve parameters of \"%s\": %s", fullName, err)
+	}
+	if r.StatusCode != 200 {
+		return nil, fmt.Errorf("unable to retrieve parameters of \"%s\": %s", fullName, r.Status)
+	}
+
+	var defs []ParameterDefinition
+	for _, p := range resp.Property {
+		for _, pd := range p.ParameterDefinitions {
+			def := ParameterDefinition{
+				Name:        pd.Name,
+				Type:        parameterType(pd.Type),
+				Description: pd.Description,
+				Choices:     pd.Choices,
+			}
+			if pd.DefaultParameterValue != nil {
+				def.Default = pd.DefaultParameterValue.Value
+			}
+			defs = append(defs, def)
+		}
+	}
+
+	return defs, nil
+}
+
+// Returns the short name of a parameter type, e.g. "boolean" for "BooleanParameterDefinition"
+func parameterType(t string) string {
+	return strings.ToLower(strings.TrimSuffix(t, "ParameterDefinition"))
+}
+
+// Parses KEY=VALUE arguments into a map of parameter values
+func parseParameters(args []string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, arg := range args {
+		if !strings.Contains(arg, "=") {
+			return nil, fmt.Errorf("invalid parameter \"%s\": expected KEY=VALUE", arg)
+		}
+		key, value := splitKeyValue(arg)
+		params[key] = value
+	}
+	return params, nil
+}
+
+/*
+	Checks parameter values against a job's definitions before triggering it: every name must be defined,
+	choice parameters must be one of their choices, and boolean parameters must be true or false. All the
+	problems found are reported together.
+*/
+func validateParameters(fullName string, defs []ParameterDefinition, params map[string]string) error {
+	if len(params) > 0 && len(defs) == 0 {
+		return fmt.Errorf("%s does not take parameters", fullName)
+	}
+
+	byName := make(map[string]ParameterDefinition)
+	var names []string
+	for _, d := range defs {
+		byName[d.Name] = d
+		names = append(names, d.Name)
+	}
+
+	var keys []string
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, k := range keys {
+		v := params[k]
+		d, ok := byName[k]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("unknown parameter \"%s\" (expected one of %s)", k, strings.Join(names, ", ")))
+		case d.Type == "choice" && !containsString(d.Choices, v):
+			problems = append(problems, fmt.Sprintf("invalid value \"%s\" for choice parameter \"%s\" (expected one of %s)", v, k, strings.Join(d.Choices, ", ")))
+		case d.Type == "boolean" && v != "true" && v != "false":
+			problems = append(problems, fmt.Sprintf("invalid value \"%s\" for boolean parameter \"%s\" (expected true or false)", v, k))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid parameters for %s:\n  %s", fullName, strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Maximum time to wait for a replayed or restarted build to leave the queue
+const rerunStartTimeout = 10 * time.Minute
+
+// Script editors of the replay page, named after their form field, e.g. "_.mainScript" or "_.Script1"
+var replayScriptRegex = regexp.MustCompile(`(?s)<textarea[^>]*\bname="_\.([^"]+)"[^>]*>(.*?)</textarea>`)
+
+type ReplayOptions struct {
+	// Pipeline script replacing the script of the build
+	Jenkinsfile string
+	// Wait for the new build to complete, and fail if it is not successful
+	Wait bool
+	// Wait and stream the console output of the new build
+	Follow bool
+}
+
+type RestartOptions struct {
+	// Stage of a declarative pipeline to restart from
+	Stage  string
+	Wait   bool
+	Follow bool
+}
+
+/*
+	Replays a pipeline build with the script read from opts.Jenkinsfile instead of its original script. Scripts
+	loaded by the pipeline with the "load" step are resubmitted unchanged. With opts.Wait or opts.Follow, waits
+	for the new build like TriggerBuild does.
+*/
+func ReplayBuild(c *APIClient, jobURL string, id int64, opts ReplayOptions) error {
+	fullName := jobFullName(jobURL)
+
+	script, err := ioutil.ReadFile(opts.Jenkinsfile)
+	if err != nil {
+		return fmt.Errorf("unable to read pipeline script: %s", err)
+	}
+
+	scripts, err := getLoadedScripts(c, fullName, id)
+	if err != nil {
+		return err
+	}
+	scripts["mainScript"] = string(script)
+
+	form := url.Values{}
+	for field, text := range scripts {
+		form.Set(field, text)
+	}
+	payload, err := json.Marshal(scripts)
+	if err != nil {
+		return err
+	}
+	form.Set("json", string(payload))
+
+	endpoint := fmt.Sprintf("%s/%d/replay/run", jobBase(fullName), id)
+	label := fmt.Sprintf("replay of %s #%d", fullName, id)
+
+	return rerunBuild(c, fullName, id, endpoint, form, label, opts.Wait, opts.Follow)
+}
+
+/*
+	Returns the scripts loaded by a pipeline build with the "load" step, keyed by their field in the replay form,
+	from the editors of its replay page.
+*/
+func getLoadedScripts(c *APIClient, fullName string, id int64) (map[string]string, error) {
+	var page string
+	resp, err := c.Client.Requester.Get(c.Context, fmt.Sprintf("%s/%d/replay/", jobBase(fullName), id), &page, map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the replay page of %s #%d: %s", fullName, id, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s #%d cannot be replayed: it is not a pipeline build, or its job does not allow replays", fullName, id)
+	default:
+		return nil, fmt.Errorf("unable to retrieve the replay page of %s #%d: %s", fullName, id, resp.Status)
+	}
+
+	scripts := make(map[string]string)
+	for _, m := range replayScriptRegex.FindAllStringSubmatch(page, -1) {
+		if m[1] == "mainScript" {
+			continue
+		}
+		// like browsers, ignore the newline following the start tag
+		scripts[m[1]] = strings.TrimPrefix(html.UnescapeString(m[2]), "\n")
+	}
+
+	return scripts, nil
+}
+
+/*
+	Restarts a completed declarative pipeline build from one of its stages, skipping the stages before it. With
+	opts.Wait or opts.Follow, waits for the new build like TriggerBuild does.
+*/
+func RestartBuildFromStage(c *APIClient, jobURL string, id int64, opts RestartOptions) error {
+	fullName := jobFullName(jobURL)
+
+	stages, err := getBuildStages(c, fullName, id)
+	if err != nil {
+		return err
+	}
+	if !containsString(stages, opts.Stage) {
+		return fmt.Errorf("%s #%d has no stage \"%s\" (stages: %s)", fullName, id, opts.Stage, strings.Join(stages, ", "))
+	}
+
+	form := url.Values{}
+	form.Set("stageName", opts.Stage)
+	form.Set("json", fmt.Sprintf(`{"stageName": %s}`, jsonString(opts.Stage)))
+
+	endpoint := fmt.Sprintf("%s/%d/restart/restart", jobBase(fullName), id)
+	label := fmt.Sprintf("restart of %s #%d from stage \"%s\"", fullName, id, opts.Stage)
+
+	return rerunBuild(c, fullName, id, endpoint, form, label, opts.Wait, opts.Follow)
+}
+
+/*
+	Submits a form that schedules a new build of a job from build id, e.g. a replay, and optionally waits for it.
+	Such forms do not return the queue item, so the new build is looked for from the next build number of the
+	job, skipping builds that were not started by a rerun of build id.
+*/
+func rerunBuild(c *APIClient, fullName string, id int64, endpoint string, form url.Values, label string, wait bool, follow bool) error {
+	var job struct {
+		NextBuildNumber int64 `json:"nextBuildNumber"`
+	}
+	resp, err := c.Client.Requester.GetJSON(c.Context, jobBase(fullName), &job, map[string]string{"tree": "nextBuildNumber"})
+	if err != nil {
+		return fmt.Errorf("unable to retrieve \"%s\": %s", fullName, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to retrieve \"%s\": %s", fullName, resp.Status)
+	}
+
+	if err := postForm(c, endpoint, form); err != nil {
+		return fmt.Errorf("failed to schedule %s: %s", label, err)
+	}
+	log.Printf("Scheduled %s", label)
+
+	if !wait && !follow {
+		return nil
+	}
+
+	number, err := waitForRerunStart(c, fullName, id, job.NextBuildNumber)
+	if err != nil {
+		return err
+	}
+	log.Printf("Started %s #%d", fullName, number)
+
+	_, err = followBuild(c, fullName, number, follow)
+	return err
+}
+
+/*
+	Waits for the build started by a replay or restart of build id to exist, i.e. to leave the queue, and returns
+	its number. Builds are checked in order from number: those with no replay or restart cause referring to
+	build id, e.g. builds that were already queued, are skipped.
+*/
+func waitForRerunStart(c *APIClient, fullName string, id int64, number int64) (int64, error) {
+	deadline := time.Now().Add(rerunStartTimeout)
+	for {
+		var build struct {
+			Actions []struct {
+				Causes []struct {
+					Class          string `json:"_class"`
+					OriginalNumber int64  `json:"originalNumber"`
+				} `json:"causes"`
+			} `json:"actions"`
+		}
+		query := map[string]string{"tree": "actions[causes[_class,originalNumber]]"}
+		resp, err := c.Client.Requester.GetJSON(c.Context, fmt.Sprintf("%s/%d", jobBase(fullName), number), &build, query)
+		if err != nil {
+			return 0, fmt.Errorf("unable to retrieve %s #%d: %s", fullName, number, err)
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			for _, a := range build.Actions {
+				for _, cause := range a.Causes {
+					if isRerunCause(cause.Class) && cause.OriginalNumber == id {
+						return number, nil
+					}
+				}
+			}
+			number++
+			continue
+		case http.StatusNotFound:
+			if time.Now().After(deadline) {
+				return 0, fmt.Errorf("the rerun of %s #%d did not start within %s", fullName, id, rerunStartTimeout)
+			}
+		default:
+			return 0, fmt.Errorf("unable to retrieve %s #%d: %s", fullName, number, resp.Status)
+		}
+		time.Sleep(buildPollInterval)
+	}
+}
+
+// Reports whether a build cause class is that of a replayed build or of a declarative pipeline restarted from a stage
+func isRerunCause(class string) bool {
+	switch class {
+	case "org.jenkinsci.plugins.workflow.cps.replay.ReplayCause",
+		"org.jenkinsci.plugins.pipeline.modeldefinition.causes.RestartDeclarativePipelineCause":
+		return true
+	}
+	return false
+}
+
+// Returns the names of the stages of a pipeline build, from the pipeline REST API
+func getBuildStages(c *APIClient, fullName string, number int64) ([]string, error) {
+	var describe struct {
+		Stages []struct {
+			Name string `json:"name"`
+		} `json:"stages"`
+	}
+	endpoint := fmt.Sprintf("%s/%d/wfapi/describe", jobBase(fullName), number)
+	resp, err := c.Client.Requester.Get(c.Context, endpoint, &describe, map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve stages of %s #%d: %s", fullName, number, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to retrieve stages of %s #%d: %s", fullName, number, resp.Status)
+	}
+
+	var stages []string
+	for _, s := range describe.Stages {
+		stages = append(stages, s.Name)
+	}
+
+	return stages, nil
+}
@@ -0,0 +1,202 @@
+package jenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+// Matched text longer than this is shortened around the match in the output
+const maxSnippetLength = 120
+
+type SearchOptions struct {
+	Folder string
+	XPath  string
+	Regex  string
+	Output string
+}
+
+type SearchMatch struct {
+	Job     string `json:"job"`
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Snippet string `json:"snippet"`
+}
+
+/*
+	Searches the config.xml of every job in a folder, recursively. Elements selected by the xpath expression,
+	or all elements if it is empty, are matched against the regular expression; for elements with children,
+	the text of their descendants is matched. Each matching line of text is reported as a separate match.
+*/
+func SearchJobs(c *APIClient, opts SearchOptions) error {
+	if opts.XPath == "" && opts.Regex == "" {
+		return fmt.Errorf("at least one of --xpath or --regex is required")
+	}
+
+	xpath, err := compileXPath(opts.XPath)
+	if opts.XPath == "" {
+		xpath, err = compileXPath("/*")
+	}
+	if err != nil {
+		return err
+	}
+
+	var re *regexp.Regexp
+	if opts.Regex != "" {
+		if re, err = regexp.Compile(opts.Regex); err != nil {
+			return fmt.Errorf("invalid regex \"%s\": %s", opts.Regex, err)
+		}
+	}
+
+	jobs, err := walkJobs(c, jobFullName(opts.Folder))
+	if err != nil {
+		return err
+	}
+	configs, errs := fetchItemConfigs(c, jobs)
+
+	var matches []SearchMatch
+	matchedJobs := 0
+	for _, j := range jobs {
+		if err, ok := errs[j.FullName]; ok {
+			log.Printf("Skipping %s: %s", j.FullName, err)
+			continue
+		}
+		root, err := parseXML(configs[j.FullName])
+		if err != nil {
+			log.Printf("Skipping %s: %s", j.FullName, err)
+			continue
+		}
+
+		found := searchConfig(root, xpath, re)
+		for i := range found {
+			found[i].Job = j.FullName
+		}
+		if len(found) > 0 {
+			matchedJobs++
+		}
+		matches = append(matches, found...)
+	}
+
+	switch opts.Output {
+	case "json":
+		if matches == nil {
+			matches = []SearchMatch{}
+		}
+		out, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "text":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "JOB\tPATH\tMATCH")
+		for _, m := range matches {
+			path := m.Path
+			if m.Line > 0 {
+				path = fmt.Sprintf("%s:%d", m.Path, m.Line)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", m.Job, path, m.Snippet)
+		}
+		w.Flush()
+		fmt.Printf("\n%d matches in %d of %d jobs\n", len(matches), matchedJobs, len(jobs))
+	default:
+		return fmt.Errorf("invalid output format \"%s\": expected text or json", opts.Output)
+	}
+
+	return nil
+}
+
+// Returns the matches of re in the nodes of a config selected by xpath, without the job name
+func searchConfig(root *xmlNode, xpath *xpathExpr, re *regexp.Regexp) []SearchMatch {
+	var matches []SearchMatch
+	seen := make(map[*xmlNode]bool)
+
+	for _, selected := range xpath.Select(root) {
+		// without a regex, the selected nodes themselves are the matches
+		if re == nil {
+			matches = append(matches, SearchMatch{Path: selected.Path(), Snippet: nodeSnippet(selected)})
+			continue
+		}
+
+		for _, n := range selected.descendants() {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+
+			matches = append(matches, matchText(n, re)...)
+			for _, a := range n.Attrs {
+				matches = append(matches, matchText(&xmlNode{Name: "@" + a.Name.Local, Text: a.Value, Parent: n}, re)...)
+			}
+		}
+	}
+
+	return matches
+}
+
+// Returns a match for each line of the text of n matching re
+func matchText(n *xmlNode, re *regexp.Regexp) []SearchMatch {
+	text := strings.TrimSpace(n.Text)
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+	var matches []SearchMatch
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+
+		m := SearchMatch{Path: n.Path(), Snippet: snippet(strings.TrimSpace(line), re)}
+		if len(lines) > 1 {
+			m.Line = i + 1
+		}
+		matches = append(matches, m)
+	}
+
+	return matches
+}
+
+// Returns the text of a leaf node, or the first line of the rendered element otherwise
+func nodeSnippet(n *xmlNode) string {
+	if len(n.Children) == 0 {
+		return snippet(strings.SplitN(strings.TrimSpace(n.Text), "\n", 2)[0], nil)
+	}
+	return strings.TrimSpace(strings.SplitN(n.String(), "\n", 2)[0])
+}
+
+// Shortens a line to at most maxSnippetLength characters, keeping the first match of re in view
+func snippet(line string, re *regexp.Regexp) string {
+	if len(line) <= maxSnippetLength {
+		return line
+	}
+
+	start := 0
+	if re != nil {
+		if loc := re.FindStringIndex(line); loc != nil && loc[1] > maxSnippetLength-3 {
+			// keep some text before the match for context
+			start = loc[0] - 20
+			if start < 0 {
+				start = 0
+			}
+		}
+	}
+	end := start + maxSnippetLength - 6
+	if end > len(line) {
+		end = len(line)
+	}
+
+	s := line[start:end]
+	if start > 0 {
+		s = "..." + s
+	}
+	if end < len(line) {
+		s += "..."
+	}
+	return s
+}
@@ -0,0 +1,347 @@
+package jenkins
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Number of preceding builds used as the baseline when looking for duration anomalies
+const anomalyBaselineBuilds = 20
+
+type StatsOptions struct {
+	Since     string
+	Output    string
+	Anomalies bool
+	Threshold float64
+}
+
+/*
+	JobStats summarizes the builds of a job over a period. Period is either "all" or an ISO week, e.g. "2026-W40".
+	Percentiles are -1 when no build of the period has the time recorded.
+*/
+type JobStats struct {
+	Job         string  `json:"job"`
+	Period      string  `json:"period"`
+	Builds      int     `json:"builds"`
+	SuccessRate float64 `json:"successRate"`
+	QueueP50Ms  int64   `json:"queueP50Ms"`
+	QueueP90Ms  int64   `json:"queueP90Ms"`
+	QueueP99Ms  int64   `json:"queueP99Ms"`
+	ExecP50Ms   int64   `json:"executionP50Ms"`
+	ExecP90Ms   int64   `json:"executionP90Ms"`
+	ExecP99Ms   int64   `json:"executionP99Ms"`
+}
+
+// BuildAnomaly is a build whose duration deviates from the median of the builds preceding it
+type BuildAnomaly struct {
+	Job        string    `json:"job"`
+	BuildID    int64     `json:"buildId"`
+	Scheduled  time.Time `json:"scheduled"`
+	DurationMs int64     `json:"durationMs"`
+	BaselineMs int64     `json:"baselineMs"`
+	Score      float64   `json:"score"`
+}
+
+type StatsReport struct {
+	Jobs      []JobStats     `json:"jobs"`
+	Weeks     []JobStats     `json:"weeks"`
+	Anomalies []BuildAnomaly `json:"anomalies"`
+}
+
+// GetBuildStats syncs the build cache for the specified job or folder and reports statistics on the cached builds
+func GetBuildStats(c *APIClient, bc *BuildCache, jobURL string, opts StatsOptions) error {
+	builds, err := cachedBuildsSince(c, bc, jobURL, opts.Since)
+	if err != nil {
+		return err
+	}
+
+	report := computeBuildStats(builds, opts.Threshold)
+
+	switch opts.Output {
+	case "json":
+		var v interface{} = report
+		if opts.Anomalies {
+			anomalies := report.Anomalies
+			if anomalies == nil {
+				anomalies = []BuildAnomaly{}
+			}
+			v = anomalies
+		}
+		reportJSON, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode stats as JSON: %s", err)
+		}
+		fmt.Printf("%s\n", reportJSON)
+	case "csv":
+		if opts.Anomalies {
+			return writeAnomaliesCSV(report.Anomalies)
+		}
+		return writeStatsCSV(append(report.Jobs, report.Weeks...))
+	case "", "text":
+		if opts.Anomalies {
+			for _, a := range report.Anomalies {
+				a.PrintBuildAnomaly()
+			}
+			return nil
+		}
+		for _, s := range report.Jobs {
+			s.PrintJobStats()
+		}
+		for _, s := range report.Weeks {
+			s.PrintJobStats()
+		}
+	default:
+		return fmt.Errorf("unsupported output format \"%s\"", opts.Output)
+	}
+
+	return nil
+}
+
+/*
+	Brings the build cache up to date for the specified job or folder and returns the cached builds
+	scheduled after since.
+*/
+func cachedBuildsSince(c *APIClient, bc *BuildCache, jobURL string, since string) ([]CachedBuild, error) {
+	cutoff, err := parseSince(since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := SyncBuildCache(c, bc, jobURL); err != nil {
+		return nil, err
+	}
+
+	cached, err := bc.Builds(cacheInstanceKey(c.Client.Server), jobFullName(jobURL))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read build cache: %s", err)
+	}
+
+	var builds []CachedBuild
+	for _, b := range cached {
+		if !b.Build.ScheduledTimestamp.Before(cutoff) {
+			builds = append(builds, b)
+		}
+	}
+
+	return builds, nil
+}
+
+func computeBuildStats(builds []CachedBuild, threshold float64) StatsReport {
+	var report StatsReport
+
+	byJob := make(map[string][]CachedBuild)
+	byWeek := make(map[[2]string][]CachedBuild)
+	for _, b := range builds {
+		week := [2]string{b.Job, isoWeek(b.Build.ScheduledTimestamp)}
+		byJob[b.Job] = append(byJob[b.Job], b)
+		byWeek[week] = append(byWeek[week], b)
+	}
+
+	for job, jobBuilds := range byJob {
+		report.Jobs = append(report.Jobs, summarizeBuilds(job, "all", jobBuilds))
+		report.Anomalies = append(report.Anomalies, findDurationAnomalies(jobBuilds, threshold)...)
+	}
+	for key, weekBuilds := range byWeek {
+		report.Weeks = append(report.Weeks, summarizeBuilds(key[0], key[1], weekBuilds))
+	}
+
+	sort.Slice(report.Jobs, func(i, j int) bool { return report.Jobs[i].Job < report.Jobs[j].Job })
+	sort.Slice(report.Weeks, func(i, j int) bool {
+		if report.Weeks[i].Job != report.Weeks[j].Job {
+			return report.Weeks[i].Job < report.Weeks[j].Job
+		}
+		return report.Weeks[i].Period < report.Weeks[j].Period
+	})
+	sort.Slice(report.Anomalies, func(i, j int) bool { return report.Anomalies[i].Scheduled.Before(report.Anomalies[j].Scheduled) })
+
+	return report
+}
+
+func summarizeBuilds(job string, period string, builds []CachedBuild) JobStats {
+	var queue, exec []int64
+	succeeded := 0
+	for _, b := range builds {
+		if b.Result == "SUCCESS" {
+			succeeded++
+		}
+		// the metrics plugin did not report a value
+		if b.Build.QueueTimeMs != nil {
+			queue = append(queue, *b.Build.QueueTimeMs)
+		}
+		// -1 indicates the metrics plugin did not report a value
+		if b.Build.ExecutionTimeMs >= 0 {
+			exec = append(exec, b.Build.ExecutionTimeMs)
+		}
+	}
+
+	return JobStats{
+		Job:         job,
+		Period:      period,
+		Builds:      len(builds),
+		SuccessRate: float64(succeeded) / float64(len(builds)),
+		QueueP50Ms:  percentile(queue, 50),
+		QueueP90Ms:  percentile(queue, 90),
+		QueueP99Ms:  percentile(queue, 99),
+		ExecP50Ms:   percentile(exec, 50),
+		ExecP90Ms:   percentile(exec, 90),
+		ExecP99Ms:   percentile(exec, 99),
+	}
+}
+
+/*
+	Flags builds whose duration deviates from the median of the preceding builds by more than threshold
+	times the median absolute deviation (scaled to be comparable to a standard deviation).
+*/
+func findDurationAnomalies(builds []CachedBuild, threshold float64) []BuildAnomaly {
+	sorted := make([]CachedBuild, len(builds))
+	copy(sorted, builds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Build.BuildID < sorted[j].Build.BuildID })
+
+	var anomalies []BuildAnomaly
+	for i, b := range sorted {
+		start := i - anomalyBaselineBuilds
+		if start < 0 {
+			start = 0
+		}
+		// require a minimal baseline before judging a build
+		if i-start < 5 {
+			continue
+		}
+
+		var baseline []float64
+		for _, p := range sorted[start:i] {
+			baseline = append(baseline, float64(p.Build.DurationMs))
+		}
+
+		m := median(baseline)
+		var deviations []float64
+		for _, d := range baseline {
+			deviations = append(deviations, math.Abs(d-m))
+		}
+		mad := 1.4826 * median(deviations)
+		if mad == 0 {
+			continue
+		}
+
+		score := (float64(b.Build.DurationMs) - m) / mad
+		if math.Abs(score) > threshold {
+			anomalies = append(anomalies, BuildAnomaly{
+				Job:        b.Job,
+				BuildID:    b.Build.BuildID,
+				Scheduled:  b.Build.ScheduledTimestamp,
+				DurationMs: b.Build.DurationMs,
+				BaselineMs: int64(m),
+				Score:      score,
+			})
+		}
+	}
+
+	return anomalies
+}
+
+// Returns the pth percentile of values using the nearest-rank method, or -1 if values is empty
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return -1
+	}
+
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func isoWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func writeStatsCSV(stats []JobStats) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"job", "period", "builds", "success_rate", "queue_p50_ms", "queue_p90_ms", "queue_p99_ms", "execution_p50_ms", "execution_p90_ms", "execution_p99_ms"})
+	for _, s := range stats {
+		w.Write([]string{
+			s.Job,
+			s.Period,
+			strconv.Itoa(s.Builds),
+			strconv.FormatFloat(s.SuccessRate, 'f', 4, 64),
+			formatPercentile(s.QueueP50Ms, 1),
+			formatPercentile(s.QueueP90Ms, 1),
+			formatPercentile(s.QueueP99Ms, 1),
+			formatPercentile(s.ExecP50Ms, 1),
+			formatPercentile(s.ExecP90Ms, 1),
+			formatPercentile(s.ExecP99Ms, 1),
+		})
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func writeAnomaliesCSV(anomalies []BuildAnomaly) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"job", "build_id", "scheduled", "duration_ms", "baseline_ms", "score"})
+	for _, a := range anomalies {
+		w.Write([]string{
+			a.Job,
+			strconv.FormatInt(a.BuildID, 10),
+			a.Scheduled.Format(time.RFC3339),
+			strconv.FormatInt(a.DurationMs, 10),
+			strconv.FormatInt(a.BaselineMs, 10),
+			strconv.FormatFloat(a.Score, 'f', 2, 64),
+		})
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func (s *JobStats) PrintJobStats() {
+	if s.Period == "all" {
+		fmt.Printf("Job: %s\n", s.Job)
+	} else {
+		fmt.Printf("Job: %s (week %s)\n", s.Job, s.Period)
+	}
+	fmt.Printf("  Builds: %d\n", s.Builds)
+	fmt.Printf("  Success rate: %.1f%%\n", s.SuccessRate*100)
+	fmt.Printf("  Queue time(s): p50=%s p90=%s p99=%s\n", formatPercentile(s.QueueP50Ms, 1000),
+		formatPercentile(s.QueueP90Ms, 1000), formatPercentile(s.QueueP99Ms, 1000))
+	fmt.Printf("  Execution time(s): p50=%s p90=%s p99=%s\n", formatPercentile(s.ExecP50Ms, 1000),
+		formatPercentile(s.ExecP90Ms, 1000), formatPercentile(s.ExecP99Ms, 1000))
+}
+
+// Returns a percentile in milliseconds divided by unit, or "unknown" if there was no data to compute it from
+func formatPercentile(ms int64, unit int64) string {
+	if ms < 0 {
+		return "unknown"
+	}
+	return strconv.FormatInt(ms/unit, 10)
+}
+
+func (a *BuildAnomaly) PrintBuildAnomaly() {
+	fmt.Printf("%s #%d (%s): took %ds, baseline %ds, score %.1f\n", a.Job, a.BuildID, a.Scheduled.Format(time.RFC3339), a.DurationMs/1000, a.BaselineMs/1000, a.Score)
+}
@@ -0,0 +1,353 @@
+package jenkins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Marker added to the description of jobs created or updated by sync, identifying the jobs it may prune
+const defaultSyncMarker = "[managed by tronci sync]"
+
+type SyncOptions struct {
+	// Directory of config.xml files: dir/a/b/config.xml is the config of job a/b
+	Dir string
+	// Folder the directory is mirrored onto (default is the root of the jenkins instance)
+	Folder string
+	// Delete managed jobs and folders that are not in the directory
+	Prune  bool
+	Marker string
+	// Print the config diff of each job to update
+	Diff bool
+	// Apply without asking for confirmation
+	Yes bool
+}
+
+type syncAction struct {
+	Kind   byte // '+' to create, '~' to update, '-' to delete
+	Name   string
+	Folder bool
+	Config string
+	Diff   string
+}
+
+// An item of the sync directory. Config is empty for a directory without a config.xml, i.e. a plain folder.
+type syncItem struct {
+	Config string
+	Folder bool
+}
+
+// PlanSync prints the changes that ApplySync would make
+func PlanSync(c *APIClient, opts SyncOptions) error {
+	_, err := planSync(c, opts)
+	return err
+}
+
+/*
+	Mirrors a directory tree of config.xml files onto jenkins: directories are folders, and each config.xml is
+	the config of the job or folder at that path. Missing items are created and changed ones updated; with
+	opts.Prune, jobs and folders marked as managed by sync that are no longer in the directory are deleted.
+*/
+func ApplySync(c *APIClient, opts SyncOptions) error {
+	actions, err := planSync(c, opts)
+	if err != nil || len(actions) == 0 {
+		return err
+	}
+
+	if !opts.Yes && !confirm("Apply this plan?") {
+		return fmt.Errorf("aborted")
+	}
+
+	failed := 0
+	for _, a := range actions {
+		var err error
+		switch a.Kind {
+		case '+':
+			err = createJob(c, a.Name, a.Config)
+		case '~':
+			err = updateJobConfig(c, a.Name, a.Config)
+		case '-':
+			if err = postForm(c, jobBase(a.Name)+"/doDelete", nil); err != nil {
+				err = fmt.Errorf("failed to delete \"%s\": %s", a.Name, err)
+			}
+		}
+		if err != nil {
+			log.Printf("%s", err)
+			failed++
+			continue
+		}
+		log.Printf("%s %s", map[byte]string{'+': "Created", '~': "Updated", '-': "Deleted"}[a.Kind], a.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d changes failed", failed, len(actions))
+	}
+
+	return nil
+}
+
+// Compares the directory with jenkins, prints the plan and returns its actions in the order they must be applied
+func planSync(c *APIClient, opts SyncOptions) ([]syncAction, error) {
+	marker := opts.Marker
+	if marker == "" {
+		marker = defaultSyncMarker
+	}
+	scope := jobFullName(opts.Folder)
+
+	local, err := readSyncDir(opts.Dir, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := walkTree(c, scope, true)
+	if err != nil {
+		return nil, err
+	}
+	remote := make(map[string]JobRef)
+	for _, item := range items {
+		if !isComputedChild(item.FullName, items) {
+			remote[item.FullName] = item
+		}
+	}
+
+	var jobs []JobRef
+	for _, item := range remote {
+		jobs = append(jobs, item)
+	}
+	configs, err := getJobConfigs(c, jobs)
+	if err != nil {
+		return nil, err
+	}
+	for name, item := range remote {
+		// folder configs are only needed to compare them, or to check their marker
+		if _, ok := local[name]; item.IsFolder() && (!ok || local[name].Config != "") {
+			if configs[name], err = getJobConfig(c, name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var names []string
+	for name := range local {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var actions []syncAction
+	unchanged := 0
+	for _, name := range names {
+		item := local[name]
+		existing, exists := remote[name]
+		if exists && existing.IsFolder() != item.Folder {
+			return nil, fmt.Errorf("%s is a %s on jenkins but not in %s", name, existing.Type(), opts.Dir)
+		}
+
+		config := item.Config
+		if config == "" {
+			if exists {
+				unchanged++
+				continue
+			}
+			if config, err = renderFolderConfig(FolderOptions{HealthMetric: "worst-child"}); err != nil {
+				return nil, err
+			}
+		}
+		if config, err = withDescriptionMarker(config, marker); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+
+		if !exists {
+			actions = append(actions, syncAction{Kind: '+', Name: name, Folder: item.Folder, Config: config})
+			continue
+		}
+
+		current, err := normalizeXML(configs[name])
+		if err != nil {
+			return nil, fmt.Errorf("current config of \"%s\": %s", name, err)
+		}
+		desired, _ := normalizeXML(config)
+		if current == desired {
+			unchanged++
+			continue
+		}
+		actions = append(actions, syncAction{Kind: '~', Name: name, Folder: item.Folder, Config: config,
+			Diff: unifiedDiff(current, desired, name+" (jenkins)", name+" ("+opts.Dir+")", 3)})
+	}
+
+	// deepest items first, so that folders are only pruned once their contents are
+	var extra []string
+	for name := range remote {
+		if _, ok := local[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(extra)))
+
+	pruned := make(map[string]bool)
+	unmanaged := 0
+	for _, name := range extra {
+		managed := hasDescriptionMarker(configs[name], marker)
+		if managed && remote[name].IsFolder() {
+			// a folder is deleted with its contents, which must all be pruned as well
+			for other := range remote {
+				if strings.HasPrefix(other, name+"/") && !pruned[other] {
+					managed = false
+				}
+			}
+		}
+		if !managed || !opts.Prune {
+			unmanaged++
+			continue
+		}
+		pruned[name] = true
+		actions = append(actions, syncAction{Kind: '-', Name: name, Folder: remote[name].IsFolder()})
+	}
+
+	for _, a := range actions {
+		kind := "job"
+		if a.Folder {
+			kind = "folder"
+		}
+		fmt.Printf("  %c %s (%s)\n", a.Kind, a.Name, kind)
+		if opts.Diff && a.Diff != "" {
+			fmt.Print(a.Diff)
+		}
+	}
+
+	creates, updates := 0, 0
+	for _, a := range actions {
+		switch a.Kind {
+		case '+':
+			creates++
+		case '~':
+			updates++
+		}
+	}
+	if len(actions) == 0 {
+		fmt.Printf("No changes: jenkins matches %s\n", opts.Dir)
+	} else {
+		fmt.Printf("\nPlan: %d to create, %d to update, %d to delete, %d unchanged\n", creates, updates, len(pruned), unchanged)
+	}
+	if unmanaged > 0 {
+		fmt.Printf("%d items on jenkins are not in %s and are left alone (only items marked %q are pruned, with --prune)\n", unmanaged, opts.Dir, marker)
+	}
+
+	return actions, nil
+}
+
+// Returns the items of the sync directory keyed by their full name on jenkins, below scope
+func readSyncDir(dir string, scope string) (map[string]syncItem, error) {
+	items := make(map[string]syncItem)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || path == dir {
+			return nil
+		}
+		// e.g. .git
+		if strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if scope != "" {
+			name = scope + "/" + name
+		}
+
+		item := syncItem{Folder: true}
+		class := "com.cloudbees.hudson.plugins.folder.Folder"
+		content, err := ioutil.ReadFile(filepath.Join(path, "config.xml"))
+		if err == nil {
+			root, err := parseXML(string(content))
+			if err != nil {
+				return fmt.Errorf("%s: %s", filepath.Join(path, "config.xml"), err)
+			}
+			class = root.Name
+			item = syncItem{Config: string(content), Folder: (JobRef{Class: class}).IsFolder()}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		items[name] = item
+
+		// the contents of multibranch projects and organization folders are created by branch indexing
+		if (JobRef{Class: class}).Type() != "folder" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read \"%s\": %s", dir, err)
+	}
+
+	return items, nil
+}
+
+// Reports whether an item is inside a multibranch project or organization folder, whose contents are computed
+func isComputedChild(fullName string, items []JobRef) bool {
+	for _, item := range items {
+		if item.IsFolder() && item.Type() != "folder" && strings.HasPrefix(fullName, item.FullName+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the top-level description element of a config, or nil if it has none
+func configDescription(root *xmlNode) *xmlNode {
+	var description *xmlNode
+	for _, child := range root.Children {
+		if child.Name == "description" {
+			description = child
+		}
+	}
+	return description
+}
+
+// Reports whether the description of a config contains marker
+func hasDescriptionMarker(config string, marker string) bool {
+	root, err := parseXML(config)
+	if err != nil {
+		return false
+	}
+	description := configDescription(root)
+	return description != nil && strings.Contains(description.Text, marker)
+}
+
+// Adds marker to the description of a config, unless it already contains it
+func withDescriptionMarker(config string, marker string) (string, error) {
+	root, err := parseXML(config)
+	if err != nil {
+		return "", err
+	}
+
+	description := configDescription(root)
+	if description == nil {
+		description = &xmlNode{Name: "description", Parent: root}
+		root.Children = append([]*xmlNode{description}, root.Children...)
+	}
+
+	if !strings.Contains(description.Text, marker) {
+		if text := strings.TrimSpace(description.Text); text != "" {
+			description.Text = text + "\n\n" + marker
+		} else {
+			description.Text = marker
+		}
+	}
+
+	result := root.String()
+	if decl := xmlDeclRegex.FindString(config); decl != "" {
+		result = strings.TrimSpace(decl) + "\n" + result
+	}
+
+	return result, nil
+}
@@ -0,0 +1,314 @@
+package jenkins
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type TransformOptions struct {
+	Folder  string
+	XPath   string
+	Regex   string
+	Replace string
+	// Only print the changes
+	Plan bool
+	// Apply the changes without asking for confirmation
+	Yes          bool
+	RollbackFile string
+}
+
+// Rollback records the configs of jobs before a transform so that they can be restored
+type Rollback struct {
+	Instance string            `json:"instance"`
+	Created  time.Time         `json:"created"`
+	Configs  map[string]string `json:"configs"`
+}
+
+/*
+	Edits the config.xml of every job in a folder, recursively, and shows the resulting diffs. Elements
+	selected by the xpath expression have their text replaced, or are replaced entirely if the replacement is
+	an XML fragment. With a regex, only the matching parts of the text of the selected elements and their
+	descendants are replaced, and the replacement may refer to submatches as $1. Unless opts.Plan is set, the
+	changes are applied after confirmation, once the previous configs have been saved to opts.RollbackFile.
+*/
+func TransformJobs(c *APIClient, opts TransformOptions) error {
+	if opts.XPath == "" && opts.Regex == "" {
+		return fmt.Errorf("at least one of --xpath or --regex is required")
+	}
+
+	xpath, err := compileXPath(opts.XPath)
+	if opts.XPath == "" {
+		xpath, err = compileXPath("/*")
+	}
+	if err != nil {
+		return err
+	}
+
+	var re *regexp.Regexp
+	if opts.Regex != "" {
+		if re, err = regexp.Compile(opts.Regex); err != nil {
+			return fmt.Errorf("invalid regex \"%s\": %s", opts.Regex, err)
+		}
+	}
+
+	jobs, err := walkJobs(c, jobFullName(opts.Folder))
+	if err != nil {
+		return err
+	}
+	configs, err := getJobConfigs(c, jobs)
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[string]string)
+	updated := make(map[string]string)
+	var names []string
+	for _, j := range jobs {
+		config, changed, err := transformConfig(configs[j.FullName], xpath, re, opts.Replace)
+		if err != nil {
+			log.Printf("Skipping %s: %s", j.FullName, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		current, _ := normalizeXML(configs[j.FullName])
+		desired, err := normalizeXML(config)
+		if err != nil {
+			log.Printf("Skipping %s: transformed config: %s", j.FullName, err)
+			continue
+		}
+		fmt.Print(unifiedDiff(current, desired, j.FullName+" (current)", j.FullName+" (transformed)", 3))
+
+		previous[j.FullName] = configs[j.FullName]
+		updated[j.FullName] = config
+		names = append(names, j.FullName)
+	}
+
+	fmt.Printf("\nPlan: %d to change, %d unchanged\n", len(names), len(jobs)-len(names))
+	if len(names) == 0 || opts.Plan {
+		return nil
+	}
+
+	if !opts.Yes && !confirm(fmt.Sprintf("Apply these changes to %d jobs?", len(names))) {
+		return fmt.Errorf("aborted")
+	}
+
+	if err := writeRollback(c, opts.RollbackFile, previous); err != nil {
+		return err
+	}
+
+	return updateJobConfigs(c, names, updated)
+}
+
+// RestoreJobs posts the configs saved in a rollback file back to their jobs
+func RestoreJobs(c *APIClient, file string, yes bool) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read \"%s\": %s", file, err)
+	}
+
+	var rollback Rollback
+	if err := json.Unmarshal(content, &rollback); err != nil {
+		return fmt.Errorf("invalid rollback file \"%s\": %s", file, err)
+	}
+	if rollback.Instance != "" && strings.TrimRight(rollback.Instance, "/") != strings.TrimRight(c.Client.Server, "/") {
+		return fmt.Errorf("rollback file \"%s\" was written for %s", file, rollback.Instance)
+	}
+
+	var names []string
+	for name := range rollback.Configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !yes && !confirm(fmt.Sprintf("Restore the configs of %d jobs saved on %s?", len(names), rollback.Created.Format(time.RFC1123))) {
+		return fmt.Errorf("aborted")
+	}
+
+	return updateJobConfigs(c, names, rollback.Configs)
+}
+
+/*
+	Returns config with the elements selected by xpath transformed as described in TransformJobs, and whether
+	it differs from the original once both are normalized. Only the transformed elements and attributes are
+	rewritten; the rest of the config, including comments and formatting, is kept as written.
+*/
+func transformConfig(config string, xpath *xpathExpr, re *regexp.Regexp, replace string) (string, bool, error) {
+	original, err := normalizeXML(config)
+	if err != nil {
+		return "", false, err
+	}
+	root, err := parseXML(config)
+	if err != nil {
+		return "", false, err
+	}
+
+	var patches []textPatch
+	fragment := re == nil && strings.HasPrefix(strings.TrimSpace(replace), "<")
+	seen := make(map[*xmlNode]bool)
+	for _, selected := range xpath.Select(root) {
+		switch {
+		case fragment:
+			if strings.HasPrefix(selected.Name, "@") {
+				return "", false, fmt.Errorf("cannot replace attribute %s with an XML fragment", selected.Path())
+			}
+			n, err := parseXML(replace)
+			if err != nil {
+				return "", false, fmt.Errorf("replacement: %s", err)
+			}
+			patches = append(patches, textPatch{selected.start, selected.end, strings.TrimSpace(replace)})
+			if selected.Parent == nil {
+				root = n
+				continue
+			}
+			n.Parent = selected.Parent
+			for i, child := range selected.Parent.Children {
+				if child == selected {
+					selected.Parent.Children[i] = n
+				}
+			}
+		case re == nil:
+			if len(selected.Children) > 0 {
+				return "", false, fmt.Errorf("%s has child elements, replace it with an XML fragment or use --regex", selected.Path())
+			}
+			patches = append(patches, selected.setText(config, replace)...)
+		default:
+			for _, n := range selected.descendants() {
+				if seen[n] {
+					continue
+				}
+				seen[n] = true
+
+				// the text of elements with children is the whitespace between them
+				if text := re.ReplaceAllString(n.Text, replace); text != n.Text && len(n.Children) == 0 {
+					patches = append(patches, n.setText(config, text)...)
+				}
+				for _, a := range n.Attrs {
+					if value := re.ReplaceAllString(a.Value, replace); value != a.Value {
+						attr := &xmlNode{Name: "@" + a.Name.Local, Text: a.Value, Parent: n}
+						patches = append(patches, attr.setText(config, value)...)
+					}
+				}
+			}
+		}
+	}
+
+	if root.String() == original {
+		return config, false, nil
+	}
+
+	return applyPatches(config, patches), true, nil
+}
+
+// A replacement of the text between two byte offsets of a document
+type textPatch struct {
+	start, end int
+	text       string
+}
+
+// Applies the patches to a document, ignoring patches within a part of the document that is already replaced
+func applyPatches(doc string, patches []textPatch) string {
+	sort.SliceStable(patches, func(i, j int) bool { return patches[i].start < patches[j].start })
+
+	var sb strings.Builder
+	offset := 0
+	for _, p := range patches {
+		if p.start < offset {
+			continue
+		}
+		sb.WriteString(doc[offset:p.start])
+		sb.WriteString(p.text)
+		offset = p.end
+	}
+	sb.WriteString(doc[offset:])
+
+	return sb.String()
+}
+
+/*
+	Sets the text of an element, or the value of an attribute selected as "@attr", and returns the patches that
+	make the same change to doc, the document the element was parsed from.
+*/
+func (n *xmlNode) setText(doc string, text string) []textPatch {
+	if strings.HasPrefix(n.Name, "@") && n.Parent != nil {
+		for i, a := range n.Parent.Attrs {
+			if "@"+a.Name.Local == n.Name {
+				n.Parent.Attrs[i].Value = text
+			}
+		}
+		n.Text = text
+		return n.Parent.attrPatches(doc, n.Name[1:], text)
+	}
+	n.Text = text
+
+	if n.contentEnd == n.tagEnd && strings.HasSuffix(doc[n.start:n.tagEnd], "/>") {
+		// an empty element tag, e.g. <a/>
+		return []textPatch{{n.tagEnd - 2, n.tagEnd, ">" + escapeXMLText(text) + "</" + n.Name + ">"}}
+	}
+	return []textPatch{{n.tagEnd, n.contentEnd, escapeXMLText(text)}}
+}
+
+// Returns the patches setting the value of an attribute in the start tag of the element in doc
+func (n *xmlNode) attrPatches(doc string, name string, value string) []textPatch {
+	tag := doc[n.start:n.tagEnd]
+	attrRegex := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+	var patches []textPatch
+	for _, m := range attrRegex.FindAllStringSubmatchIndex(tag, -1) {
+		escaped := escapeXMLAttr(value)
+		start, end := m[2], m[3]
+		if start < 0 {
+			start, end = m[4], m[5]
+			escaped = strings.NewReplacer("&", "&amp;", "<", "&lt;", "'", "&apos;").Replace(value)
+		}
+		patches = append(patches, textPatch{n.start + start, n.start + end, escaped})
+	}
+	return patches
+}
+
+func writeRollback(c *APIClient, file string, configs map[string]string) error {
+	if file == "" {
+		file = fmt.Sprintf("tronci-rollback-%s.json", time.Now().Format("20060102-150405"))
+	}
+
+	rollback := Rollback{
+		Instance: c.Client.Server,
+		Created:  time.Now(),
+		Configs:  configs,
+	}
+	// keep the XML in the configs readable
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rollback); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("unable to write rollback file: %s", err)
+	}
+
+	log.Printf("Saved the previous configs to %s", file)
+
+	return nil
+}
+
+// Posts configs to their jobs in the order of names, continuing past failures
+func updateJobConfigs(c *APIClient, names []string, configs map[string]string) error {
+	return forEachJob(names, func(fullName string) error {
+		if err := updateJobConfig(c, fullName, configs[fullName]); err != nil {
+			return err
+		}
+		log.Printf("Updated %s", fullName)
+		return nil
+	})
+}
@@ -0,0 +1,127 @@
+package jenkins
+
+import (
+	"regexp"
+	"testing"
+)
+
+const transformTestConfig = `<?xml version='1.1' encoding='UTF-8'?>
+<project>
+  <!-- managed by the platform team -->
+  <description/>
+  <scm class='hudson.plugins.git.GitSCM' plugin="git@4.0">
+    <url>https://github.com/acme/app.git</url>
+  </scm>
+  <builders>
+    <hudson.tasks.Shell>
+      <command>make &amp;&amp; make test</command>
+    </hudson.tasks.Shell>
+  </builders>
+</project>
+`
+
+func TestTransformConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		xpath   string
+		regex   string
+		replace string
+		want    string
+		changed bool
+	}{
+		{
+			name: "text", xpath: "//command", replace: "make <all>", changed: true,
+			want: `<?xml version='1.1' encoding='UTF-8'?>
+<project>
+  <!-- managed by the platform team -->
+  <description/>
+  <scm class='hudson.plugins.git.GitSCM' plugin="git@4.0">
+    <url>https://github.com/acme/app.git</url>
+  </scm>
+  <builders>
+    <hudson.tasks.Shell>
+      <command>make &lt;all&gt;</command>
+    </hudson.tasks.Shell>
+  </builders>
+</project>
+`,
+		},
+		{
+			name: "empty element", xpath: "/project/description", replace: "App", changed: true,
+			want: `<?xml version='1.1' encoding='UTF-8'?>
+<project>
+  <!-- managed by the platform team -->
+  <description>App</description>
+  <scm class='hudson.plugins.git.GitSCM' plugin="git@4.0">
+    <url>https://github.com/acme/app.git</url>
+  </scm>
+  <builders>
+    <hudson.tasks.Shell>
+      <command>make &amp;&amp; make test</command>
+    </hudson.tasks.Shell>
+  </builders>
+</project>
+`,
+		},
+		{
+			name: "regex in text and attributes", xpath: "//scm", regex: `git(@4\.0|\.GitSCM|hub)`, replace: "git'$1", changed: true,
+			want: `<?xml version='1.1' encoding='UTF-8'?>
+<project>
+  <!-- managed by the platform team -->
+  <description/>
+  <scm class='hudson.plugins.git&apos;.GitSCM' plugin="git'@4.0">
+    <url>https://git'hub.com/acme/app.git</url>
+  </scm>
+  <builders>
+    <hudson.tasks.Shell>
+      <command>make &amp;&amp; make test</command>
+    </hudson.tasks.Shell>
+  </builders>
+</project>
+`,
+		},
+		{
+			name: "fragment", xpath: "//hudson.tasks.Shell", replace: "<hudson.tasks.Maven><targets>verify</targets></hudson.tasks.Maven>", changed: true,
+			want: `<?xml version='1.1' encoding='UTF-8'?>
+<project>
+  <!-- managed by the platform team -->
+  <description/>
+  <scm class='hudson.plugins.git.GitSCM' plugin="git@4.0">
+    <url>https://github.com/acme/app.git</url>
+  </scm>
+  <builders>
+    <hudson.tasks.Maven><targets>verify</targets></hudson.tasks.Maven>
+  </builders>
+</project>
+`,
+		},
+		{
+			name: "unchanged", xpath: "//command", replace: "make && make test", changed: false,
+			want: transformTestConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := compileXPath(tt.xpath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var re *regexp.Regexp
+			if tt.regex != "" {
+				re = regexp.MustCompile(tt.regex)
+			}
+
+			got, changed, err := transformConfig(transformTestConfig, x, re, tt.replace)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed = %t, want %t", changed, tt.changed)
+			}
+			if got != tt.want {
+				t.Errorf("transformConfig() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
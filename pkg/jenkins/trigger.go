@@ -0,0 +1,204 @@
+package jenkins
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval between polls of the queue and of running builds
+const buildPollInterval = 2 * time.Second
+
+// Maximum time to wait for a triggered build to leave the queue
+const queueStartTimeout = 30 * time.Minute
+
+type TriggerOptions struct {
+	// Parameter values as KEY=VALUE
+	Params []string
+	// Wait for the build to complete, and fail if it is not successful
+	Wait bool
+	// Wait and stream the console output of the build
+	Follow bool
+}
+
+// Status of a build, as returned by the build API
+type buildStatus struct {
+	Number   int64  `json:"number"`
+	URL      string `json:"url"`
+	Result   string `json:"result"`
+	Building bool   `json:"building"`
+	Duration int64  `json:"duration"`
+}
+
+/*
+	Triggers a build of a job after validating the parameter values against the job's definitions. With
+	opts.Wait or opts.Follow, waits for the build to start and complete, and returns an error if its result is
+	not SUCCESS.
+*/
+func TriggerBuild(c *APIClient, jobURL string, opts TriggerOptions) error {
+	fullName := jobFullName(jobURL)
+
+	params, err := parseParameters(opts.Params)
+	if err != nil {
+		return err
+	}
+	defs, err := getJobParameters(c, fullName)
+	if err != nil {
+		return err
+	}
+	if err := validateParameters(fullName, defs, params); err != nil {
+		return err
+	}
+
+	queueID, err := triggerBuild(c, fullName, len(defs) > 0, params)
+	if err != nil {
+		return err
+	}
+	log.Printf("Queued %s (queue item %d)", fullName, queueID)
+
+	if !opts.Wait && !opts.Follow {
+		return nil
+	}
+
+	number, err := waitForQueueItem(c, queueID)
+	if err != nil {
+		return fmt.Errorf("%s: %s", fullName, err)
+	}
+	log.Printf("Started %s #%d", fullName, number)
+
+	_, err = followBuild(c, fullName, number, opts.Follow)
+	return err
+}
+
+/*
+	POSTs to the build endpoint of a job, or to buildWithParameters if the job is parameterized, and returns the
+	ID of the queue item from the Location header of the response.
+*/
+func triggerBuild(c *APIClient, fullName string, parameterized bool, params map[string]string) (int64, error) {
+	endpoint := jobBase(fullName) + "/build"
+	form := url.Values{}
+	if parameterized {
+		endpoint = jobBase(fullName) + "/buildWithParameters"
+		for k, v := range params {
+			form.Set(k, v)
+		}
+	}
+
+	resp, err := c.Client.Requester.Post(c.Context, endpoint, bytes.NewBufferString(form.Encode()), nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trigger %s: %s", fullName, err)
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to trigger %s: %s", fullName, resp.Status)
+	}
+
+	return queueItemID(resp.Header.Get("Location"))
+}
+
+// Returns the ID of a queue item given its URL, e.g. "https://jenkins/queue/item/42/"
+func queueItemID(location string) (int64, error) {
+	u, err := url.Parse(location)
+	if err != nil || !strings.Contains(u.Path, "/queue/item/") {
+		return 0, fmt.Errorf("no queue item in the response (Location: \"%s\")", location)
+	}
+	return strconv.ParseInt(path.Base(strings.TrimSuffix(u.Path, "/")), 10, 64)
+}
+
+/*
+	Waits for a queue item to leave the queue and returns the number of the build it started. Gives up after
+	queueStartTimeout, e.g. when no agent with the job's label is online; the item is left in the queue.
+*/
+func waitForQueueItem(c *APIClient, id int64) (int64, error) {
+	deadline := time.Now().Add(queueStartTimeout)
+	for {
+		var item struct {
+			Cancelled  bool   `json:"cancelled"`
+			Why        string `json:"why"`
+			Executable *struct {
+				Number int64 `json:"number"`
+			} `json:"executable"`
+		}
+		resp, err := c.Client.Requester.GetJSON(c.Context, fmt.Sprintf("/queue/item/%d", id), &item, map[string]string{})
+		if err != nil {
+			return 0, fmt.Errorf("unable to retrieve queue item %d: %s", id, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("unable to retrieve queue item %d: %s", id, resp.Status)
+		}
+
+		switch {
+		case item.Cancelled:
+			return 0, fmt.Errorf("queue item %d was cancelled", id)
+		case item.Executable != nil && item.Executable.Number > 0:
+			return item.Executable.Number, nil
+		case time.Now().After(deadline):
+			return 0, fmt.Errorf("queue item %d did not start within %s: %s", id, queueStartTimeout, item.Why)
+		}
+		time.Sleep(buildPollInterval)
+	}
+}
+
+/*
+	Waits for a build to complete, streaming its console output to stdout if stream is set. Returns the final
+	status of the build, and an error if its result is not SUCCESS.
+*/
+func followBuild(c *APIClient, fullName string, number int64, stream bool) (buildStatus, error) {
+	base := fmt.Sprintf("%s/%d", jobBase(fullName), number)
+
+	var offset int64
+	for {
+		var status buildStatus
+		resp, err := c.Client.Requester.GetJSON(c.Context, base, &status, map[string]string{"tree": "number,url,result,building,duration"})
+		if err != nil {
+			return status, fmt.Errorf("unable to retrieve %s #%d: %s", fullName, number, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return status, fmt.Errorf("unable to retrieve %s #%d: %s", fullName, number, resp.Status)
+		}
+
+		more := false
+		if stream {
+			if offset, more, err = streamConsole(c, base, offset); err != nil {
+				return status, fmt.Errorf("unable to retrieve the console output of %s #%d: %s", fullName, number, err)
+			}
+		}
+
+		if !status.Building && !more {
+			log.Printf("Finished %s #%d: %s in %s (%s)", fullName, number, status.Result,
+				time.Duration(status.Duration)*time.Millisecond, status.URL)
+			if status.Result != "SUCCESS" {
+				return status, fmt.Errorf("%s #%d finished with result %s", fullName, number, status.Result)
+			}
+			return status, nil
+		}
+		if status.Building {
+			time.Sleep(buildPollInterval)
+		}
+	}
+}
+
+// Prints the console output of a build from offset, and returns the new offset and whether there is more to come
+func streamConsole(c *APIClient, base string, offset int64) (int64, bool, error) {
+	var text string
+	resp, err := c.Client.Requester.Get(c.Context, base+"/logText/progressiveText", &text, map[string]string{"start": strconv.FormatInt(offset, 10)})
+	if err != nil {
+		return offset, false, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return offset, false, fmt.Errorf("%s", resp.Status)
+	}
+
+	fmt.Fprint(os.Stdout, text)
+
+	if size, err := strconv.ParseInt(resp.Header.Get("X-Text-Size"), 10, 64); err == nil {
+		offset = size
+	}
+	return offset, resp.Header.Get("X-More-Data") == "true", nil
+}
@@ -0,0 +1,220 @@
+package jenkins
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const builtInNodeName = "built-in"
+
+// Node of builds whose agent is not known, e.g. pipelines whose stages did not report the node they ran on
+const unknownNodeName = "unknown"
+
+type UsageOptions struct {
+	Since   string
+	GroupBy string
+	Output  string
+	// Cost per executor-hour keyed by node label. The "default" key applies to nodes without a priced label.
+	LabelRates map[string]float64
+}
+
+// UsageRecord is the executor time consumed by a group of builds
+type UsageRecord struct {
+	Group         string
+	Builds        int
+	ExecutorHours float64
+	Cost          float64
+}
+
+// GetUsage reports the executor-hours consumed by builds at or below jobURL, grouped as specified by opts.GroupBy
+func GetUsage(c *APIClient, bc *BuildCache, jobURL string, opts UsageOptions) error {
+	groupBy, depth, err := parseGroupBy(opts.GroupBy)
+	if err != nil {
+		return err
+	}
+
+	builds, err := cachedBuildsSince(c, bc, jobURL, opts.Since)
+	if err != nil {
+		return err
+	}
+
+	nodeLabels, err := getNodeLabels(c)
+	if err != nil {
+		return err
+	}
+
+	usageByGroup := make(map[string]*UsageRecord)
+	skipped := 0
+	for _, b := range builds {
+		// -1 indicates the metrics plugin did not report a value
+		if b.Build.ExecutionTimeMs < 0 {
+			skipped++
+			continue
+		}
+
+		node := b.Build.AgentHostMachine
+		if node == "" {
+			node = unknownNodeName
+		}
+		label := pricedLabel(nodeLabels[node], node, opts.LabelRates)
+
+		var group string
+		switch groupBy {
+		case "folder-depth":
+			segments := strings.Split(b.Job, "/")
+			if len(segments) > depth {
+				segments = segments[:depth]
+			}
+			group = strings.Join(segments, "/")
+		case "label":
+			group = label
+		case "node":
+			group = node
+		}
+
+		u, ok := usageByGroup[group]
+		if !ok {
+			u = &UsageRecord{Group: group}
+			usageByGroup[group] = u
+		}
+
+		hours := float64(b.Build.ExecutionTimeMs) / 3600000
+		u.Builds++
+		u.ExecutorHours += hours
+		u.Cost += hours * labelRate(label, opts.LabelRates)
+	}
+
+	if skipped > 0 {
+		log.Printf("Skipped %d of %d builds without an execution time", skipped, len(builds))
+	}
+
+	var usage []UsageRecord
+	for _, u := range usageByGroup {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].ExecutorHours > usage[j].ExecutorHours })
+
+	switch opts.Output {
+	case "", "csv":
+		return writeUsageCSV(usage, len(opts.LabelRates) > 0)
+	case "text":
+		for _, u := range usage {
+			u.PrintUsageRecord()
+		}
+	default:
+		return fmt.Errorf("unsupported output format \"%s\"", opts.Output)
+	}
+
+	return nil
+}
+
+/*
+	Parses a --group-by value, which is one of "label", "node" or "folder-depth=N". Returns the grouping
+	and, for folder-depth, the number of leading path segments of the job name to group by.
+*/
+func parseGroupBy(groupBy string) (string, int, error) {
+	switch {
+	case groupBy == "label" || groupBy == "node":
+		return groupBy, 0, nil
+	case strings.HasPrefix(groupBy, "folder-depth="):
+		depth, err := strconv.Atoi(strings.TrimPrefix(groupBy, "folder-depth="))
+		if err != nil || depth < 1 {
+			return "", 0, fmt.Errorf("invalid folder depth in \"%s\": expected a positive integer", groupBy)
+		}
+		return "folder-depth", depth, nil
+	}
+
+	return "", 0, fmt.Errorf("invalid grouping \"%s\": expected \"folder-depth=N\", \"label\" or \"node\"", groupBy)
+}
+
+// Returns the labels assigned to each node, keyed by node name
+func getNodeLabels(c *APIClient) (map[string][]string, error) {
+	var computers struct {
+		Computer []struct {
+			DisplayName    string `json:"displayName"`
+			AssignedLabels []struct {
+				Name string `json:"name"`
+			} `json:"assignedLabels"`
+		} `json:"computer"`
+	}
+
+	query := map[string]string{"tree": "computer[displayName,assignedLabels[name]]"}
+	resp, err := c.Client.Requester.GetJSON(c.Context, "/computer", &computers, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to list nodes: %s", resp.Status)
+	}
+
+	nodeLabels := make(map[string][]string)
+	for _, comp := range computers.Computer {
+		name := comp.DisplayName
+		if name == "master" || name == "Built-In Node" {
+			name = builtInNodeName
+		}
+		for _, l := range comp.AssignedLabels {
+			// every node carries a label matching its own name, which says nothing about its capacity
+			if l.Name != comp.DisplayName && l.Name != "master" && l.Name != "built-in" {
+				nodeLabels[name] = append(nodeLabels[name], l.Name)
+			}
+		}
+		sort.Strings(nodeLabels[name])
+	}
+
+	return nodeLabels, nil
+}
+
+/*
+	Returns the label used to account for a build that ran on a node: the first of the node's labels with a
+	configured rate, otherwise the first of its labels, otherwise the node name.
+*/
+func pricedLabel(labels []string, node string, rates map[string]float64) string {
+	for _, l := range labels {
+		if _, ok := rates[l]; ok {
+			return l
+		}
+	}
+	if len(labels) > 0 {
+		return labels[0]
+	}
+	return node
+}
+
+func labelRate(label string, rates map[string]float64) float64 {
+	if rate, ok := rates[label]; ok {
+		return rate
+	}
+	return rates["default"]
+}
+
+func writeUsageCSV(usage []UsageRecord, withCost bool) error {
+	w := csv.NewWriter(os.Stdout)
+
+	header := []string{"group", "builds", "executor_hours"}
+	if withCost {
+		header = append(header, "cost")
+	}
+	w.Write(header)
+
+	for _, u := range usage {
+		row := []string{u.Group, strconv.Itoa(u.Builds), strconv.FormatFloat(u.ExecutorHours, 'f', 2, 64)}
+		if withCost {
+			row = append(row, strconv.FormatFloat(u.Cost, 'f', 2, 64))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func (u *UsageRecord) PrintUsageRecord() {
+	fmt.Printf("%s\n  Builds: %d\n  Executor hours: %.2f\n  Cost: %.2f\n", u.Group, u.Builds, u.ExecutorHours, u.Cost)
+}
@@ -0,0 +1,53 @@
+package jenkins
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+	Converts a relative duration such as "30d", "2w" or "12h", or an absolute date such as "2026-09-01",
+	into the point in time it refers to. Relative durations are subtracted from now.
+*/
+func parseSince(since string, now time.Time) (time.Time, error) {
+	since = strings.TrimSpace(since)
+
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, since, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	units := map[string]time.Duration{
+		"w": 7 * 24 * time.Hour,
+		"d": 24 * time.Hour,
+	}
+	if len(since) > 1 {
+		if unit, ok := units[since[len(since)-1:]]; ok {
+			n, err := strconv.Atoi(since[:len(since)-1])
+			if err == nil && n >= 0 {
+				return now.Add(-time.Duration(n) * unit), nil
+			}
+		}
+	}
+
+	if d, err := time.ParseDuration(since); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time \"%s\": expected a duration such as \"30d\" or a date such as \"2026-09-01\"", since)
+}
+
+// Asks the user a yes/no question on stdin and returns true if they answer yes
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
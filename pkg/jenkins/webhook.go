@@ -0,0 +1,308 @@
+package jenkins
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/muroj/gojenkins"
+)
+
+// Endpoints receiving the webhooks of each provider, unless WebhookOptions.Endpoint is set
+var webhookEndpoints = map[string]string{
+	"github":  "/github-webhook/",
+	"gitlab":  "/generic-webhook-trigger/invoke",
+	"generic": "/generic-webhook-trigger/invoke",
+}
+
+type WebhookOptions struct {
+	// "github", "gitlab" or "generic"
+	Provider string
+	Repo     string
+	Branch   string
+	SHA      string
+	// Key of the GitHub signature, or GitLab secret token
+	Secret string
+	// Token of the Generic Webhook Trigger plugin
+	Token string
+	// Endpoint to post to instead of the default one of the provider, e.g. "/project/my-job" for the GitLab plugin
+	Endpoint string
+	// Folder searched for builds started by the webhook (default is the whole jenkins instance)
+	Folder string
+	// Time to wait for jobs to be scheduled, e.g. after polling their SCM
+	Wait time.Duration
+}
+
+// A job scheduled by a webhook
+type scheduledJob struct {
+	Job    string
+	Status string
+}
+
+/*
+	Posts a push event for a branch of a repository, as the provider would send it, to the webhook endpoint of the
+	provider's plugin and reports the jobs it scheduled. The Generic Webhook Trigger plugin reports the jobs it
+	triggered in its response; for other endpoints, the queue items and builds that appear within opts.Wait are
+	reported.
+*/
+func SendWebhook(c *APIClient, opts WebhookOptions) error {
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = webhookEndpoints[opts.Provider]
+	}
+
+	payload, headers, err := webhookPayload(opts)
+	if err != nil {
+		return err
+	}
+
+	query := map[string]string{}
+	if opts.Token != "" {
+		query["token"] = opts.Token
+	}
+	lastQueueID, err := getLastQueueID(c)
+	if err != nil {
+		return err
+	}
+
+	ar := gojenkins.NewAPIRequest("POST", endpoint, bytes.NewReader(payload))
+	if err := c.Client.Requester.SetCrumb(c.Context, ar); err != nil {
+		return err
+	}
+	ar.SetHeader("Content-Type", "application/json")
+	for k, v := range headers {
+		ar.SetHeader(k, v)
+	}
+
+	var body string
+	resp, err := c.Client.Requester.Do(c.Context, ar, &body, query)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %s", endpoint, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to post to %s: %s: %s", endpoint, resp.Status, strings.TrimSpace(body))
+	}
+	log.Printf("Posted %s push event for %s (%s) to %s", opts.Provider, opts.Repo, opts.Branch, endpoint)
+
+	// the Generic Webhook Trigger plugin reports the jobs it triggered
+	var triggered struct {
+		Jobs map[string]struct {
+			Triggered bool   `json:"triggered"`
+			URL       string `json:"url"`
+		} `json:"jobs"`
+	}
+	if err := json.Unmarshal([]byte(body), &triggered); err == nil && triggered.Jobs != nil {
+		var jobs []scheduledJob
+		for name, j := range triggered.Jobs {
+			status := "not triggered"
+			if j.Triggered {
+				status = "queued (" + j.URL + ")"
+			}
+			jobs = append(jobs, scheduledJob{Job: name, Status: status})
+		}
+		sort.Slice(jobs, func(i, j int) bool { return jobs[i].Job < jobs[j].Job })
+		printScheduledJobs(jobs, 0)
+		return nil
+	}
+
+	since := time.Now()
+	if t, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		// use the clock of the jenkins instance to find the builds started since
+		since = t.Add(-time.Second)
+	}
+	time.Sleep(opts.Wait)
+
+	jobs, err := getScheduledJobs(c, opts.Folder, lastQueueID, since)
+	if err != nil {
+		return err
+	}
+	printScheduledJobs(jobs, opts.Wait)
+
+	return nil
+}
+
+// Returns the body and headers of a push event of the provider
+func webhookPayload(opts WebhookOptions) ([]byte, map[string]string, error) {
+	repo := strings.TrimSuffix(opts.Repo, "/")
+	web := strings.TrimSuffix(repo, ".git")
+	u, err := url.Parse(web)
+	if err != nil || u.Host == "" {
+		return nil, nil, fmt.Errorf("invalid repository URL \"%s\": expected e.g. https://github.com/owner/name", opts.Repo)
+	}
+	fullName := strings.Trim(u.Path, "/")
+	owner, name := path.Split(fullName)
+	owner = strings.TrimSuffix(owner, "/")
+	ref := "refs/heads/" + opts.Branch
+	before := strings.Repeat("0", 40)
+
+	headers := make(map[string]string)
+	var event map[string]interface{}
+	switch opts.Provider {
+	case "github":
+		event = map[string]interface{}{
+			"ref":         ref,
+			"before":      before,
+			"after":       opts.SHA,
+			"head_commit": map[string]interface{}{"id": opts.SHA},
+			"commits":     []interface{}{},
+			"pusher":      map[string]interface{}{"name": "tronci"},
+			"repository": map[string]interface{}{
+				"name":           name,
+				"full_name":      fullName,
+				"owner":          map[string]interface{}{"name": owner, "login": owner},
+				"html_url":       web,
+				"url":            web,
+				"clone_url":      web + ".git",
+				"default_branch": opts.Branch,
+			},
+		}
+		headers["X-GitHub-Event"] = "push"
+	case "gitlab":
+		event = map[string]interface{}{
+			"object_kind":         "push",
+			"event_name":          "push",
+			"ref":                 ref,
+			"before":              before,
+			"after":               opts.SHA,
+			"checkout_sha":        opts.SHA,
+			"user_name":           "tronci",
+			"commits":             []interface{}{},
+			"total_commits_count": 0,
+			"project": map[string]interface{}{
+				"name":                name,
+				"path_with_namespace": fullName,
+				"web_url":             web,
+				"git_http_url":        web + ".git",
+				"default_branch":      opts.Branch,
+			},
+			"repository": map[string]interface{}{
+				"name":         name,
+				"url":          web + ".git",
+				"homepage":     web,
+				"git_http_url": web + ".git",
+			},
+		}
+		headers["X-Gitlab-Event"] = "Push Hook"
+		if opts.Secret != "" {
+			headers["X-Gitlab-Token"] = opts.Secret
+		}
+	case "generic":
+		event = map[string]interface{}{
+			"ref":        ref,
+			"branch":     opts.Branch,
+			"sha":        opts.SHA,
+			"repository": map[string]interface{}{"url": repo, "name": name, "full_name": fullName},
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid provider \"%s\": expected github, gitlab or generic", opts.Provider)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.Provider == "github" && opts.Secret != "" {
+		sig256 := hmac.New(sha256.New, []byte(opts.Secret))
+		sig256.Write(payload)
+		headers["X-Hub-Signature-256"] = "sha256=" + hex.EncodeToString(sig256.Sum(nil))
+		sig1 := hmac.New(sha1.New, []byte(opts.Secret))
+		sig1.Write(payload)
+		headers["X-Hub-Signature"] = "sha1=" + hex.EncodeToString(sig1.Sum(nil))
+	}
+
+	return payload, headers, nil
+}
+
+// Returns the ID of the most recent item in the build queue, or 0 if it is empty
+func getLastQueueID(c *APIClient) (int64, error) {
+	items, err := getQueueItems(c)
+	if err != nil {
+		return 0, err
+	}
+
+	var last int64
+	for _, item := range items {
+		if item.ID > last {
+			last = item.ID
+		}
+	}
+	return last, nil
+}
+
+type queueItem struct {
+	ID   int64  `json:"id"`
+	Why  string `json:"why"`
+	Task struct {
+		URL string `json:"url"`
+	} `json:"task"`
+}
+
+func getQueueItems(c *APIClient) ([]queueItem, error) {
+	var queue struct {
+		Items []queueItem `json:"items"`
+	}
+	if _, err := c.Client.Requester.GetJSON(c.Context, "/queue", &queue, map[string]string{"tree": "items[id,why,task[url]]"}); err != nil {
+		return nil, fmt.Errorf("unable to retrieve the build queue: %s", err)
+	}
+	return queue.Items, nil
+}
+
+// Returns the jobs queued after the queue item lastQueueID, and the jobs in folder whose last build started since
+func getScheduledJobs(c *APIClient, folder string, lastQueueID int64, since time.Time) ([]scheduledJob, error) {
+	var jobs []scheduledJob
+
+	items, err := getQueueItems(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.ID > lastQueueID {
+			name := jobFullName(strings.TrimPrefix(item.Task.URL, strings.TrimSuffix(c.Client.Server, "/")))
+			jobs = append(jobs, scheduledJob{Job: name, Status: "queued: " + item.Why})
+		}
+	}
+
+	all, err := walkJobs(c, jobFullName(folder))
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range all {
+		if j.LastBuild != nil && !time.Unix(0, j.LastBuild.Timestamp*int64(time.Millisecond)).Before(since) {
+			jobs = append(jobs, scheduledJob{Job: j.FullName, Status: fmt.Sprintf("started #%d", j.LastBuild.Number)})
+		}
+	}
+
+	return jobs, nil
+}
+
+func printScheduledJobs(jobs []scheduledJob, wait time.Duration) {
+	if len(jobs) == 0 {
+		if wait > 0 {
+			fmt.Printf("No jobs were scheduled within %s\n", wait)
+		} else {
+			fmt.Println("No jobs were scheduled")
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB\tSTATUS")
+	for _, j := range jobs {
+		fmt.Fprintf(w, "%s\t%s\n", j.Job, j.Status)
+	}
+	w.Flush()
+}
@@ -0,0 +1,192 @@
+package jenkins
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// xmlNode is a minimal DOM used to normalize, query and edit job configurations
+type xmlNode struct {
+	Name     string
+	Attrs    []xml.Attr
+	Text     string
+	Children []*xmlNode
+	Parent   *xmlNode
+
+	// Byte offsets in the parsed document of the element, the end of its start tag and the end of its content.
+	// For an empty element tag such as <a/>, tagEnd, contentEnd and end are equal.
+	start, tagEnd, contentEnd, end int
+}
+
+// Go's XML decoder only accepts version 1.0 declarations, while Jenkins writes version 1.1
+var xmlDeclRegex = regexp.MustCompile(`^\s*<\?xml[^>]*\?>`)
+
+/*
+	Parses an XML document into a tree. Whitespace between elements, comments and processing
+	instructions are discarded; namespace prefixes are kept as written.
+*/
+func parseXML(doc string) (*xmlNode, error) {
+	decl := len(xmlDeclRegex.FindString(doc))
+	d := xml.NewDecoder(strings.NewReader(doc[decl:]))
+	d.Strict = false
+
+	var root *xmlNode
+	var current *xmlNode
+	for {
+		pos := decl + int(d.InputOffset())
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid XML: %s", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{Name: qualifiedName(t.Name), Parent: current, start: pos, tagEnd: decl + int(d.InputOffset())}
+			for _, a := range t.Attr {
+				n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: qualifiedName(a.Name)}, Value: a.Value})
+			}
+			sort.Slice(n.Attrs, func(i, j int) bool { return n.Attrs[i].Name.Local < n.Attrs[j].Name.Local })
+			if current == nil {
+				if root != nil {
+					return nil, fmt.Errorf("invalid XML: multiple root elements")
+				}
+				root = n
+			} else {
+				current.Children = append(current.Children, n)
+			}
+			current = n
+		case xml.EndElement:
+			if current == nil {
+				return nil, fmt.Errorf("invalid XML: unexpected end element </%s>", qualifiedName(t.Name))
+			}
+			current.contentEnd, current.end = pos, decl+int(d.InputOffset())
+			current = current.Parent
+		case xml.CharData:
+			if current != nil {
+				current.Text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("invalid XML: no root element")
+	}
+	if current != nil {
+		return nil, fmt.Errorf("invalid XML: element <%s> is not closed", current.Name)
+	}
+
+	return root, nil
+}
+
+/*
+	Returns a canonical form of an XML document suitable for comparison: one element per line, two-space
+	indentation, sorted attributes and no XML declaration. Text content is kept verbatim.
+*/
+func normalizeXML(doc string) (string, error) {
+	root, err := parseXML(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return root.String(), nil
+}
+
+// String renders the node and its descendants in normalized form
+func (n *xmlNode) String() string {
+	var sb strings.Builder
+	n.render(&sb, 0)
+	return sb.String()
+}
+
+func (n *xmlNode) render(sb *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	sb.WriteString(indent + "<" + n.Name)
+	for _, a := range n.Attrs {
+		sb.WriteString(fmt.Sprintf(" %s=\"%s\"", a.Name.Local, escapeXMLAttr(a.Value)))
+	}
+
+	text := n.Text
+	if strings.TrimSpace(text) == "" {
+		text = ""
+	}
+
+	switch {
+	case len(n.Children) == 0 && text == "":
+		sb.WriteString("/>\n")
+	case len(n.Children) == 0:
+		sb.WriteString(">" + escapeXMLText(text) + "</" + n.Name + ">\n")
+	default:
+		sb.WriteString(">\n")
+		if text != "" {
+			sb.WriteString(indent + "  " + escapeXMLText(strings.TrimSpace(text)) + "\n")
+		}
+		for _, child := range n.Children {
+			child.render(sb, depth+1)
+		}
+		sb.WriteString(indent + "</" + n.Name + ">\n")
+	}
+}
+
+// Path returns the location of the node from the root, e.g. "/project/builders/hudson.tasks.Shell"
+func (n *xmlNode) Path() string {
+	if n.Parent == nil {
+		return "/" + n.Name
+	}
+	return n.Parent.Path() + "/" + n.Name
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Returns the trimmed text of the named child element, or def if there is no such element
+func (n *xmlNode) childText(name string, def string) string {
+	if c := n.child(name); c != nil {
+		return strings.TrimSpace(c.Text)
+	}
+	return def
+}
+
+// Returns the children of the child element of n with the given name, e.g. the entries of a list
+func (n *xmlNode) childNodes(name string) []*xmlNode {
+	if c := n.child(name); c != nil {
+		return c.Children
+	}
+	return nil
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+func escapeXMLText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func escapeXMLAttr(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", "\"", "&quot;").Replace(s)
+}
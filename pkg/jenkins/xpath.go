@@ -0,0 +1,280 @@
+package jenkins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+	xpathExpr is a compiled expression in the subset of XPath needed to address job configurations:
+	absolute (/a/b) and descendant (//a) steps, the * wildcard, "..", a final text() or @attr step, and
+	predicates of the form [n], [@attr], [@attr='value'], [child], [child='value'] and [text()='value'].
+	An expression without a leading slash is searched anywhere in the document.
+*/
+type xpathExpr struct {
+	source string
+	steps  []xpathStep
+}
+
+type xpathStep struct {
+	descendant bool
+	name       string // element name, "*", "..", "text()" or "@attr"
+	preds      []xpathPred
+}
+
+type xpathPred struct {
+	index int    // position among same-named siblings, starting at 1, or 0 for a comparison
+	name  string // "@attr", "text()" or a child element name
+	value *string
+}
+
+func compileXPath(expr string) (*xpathExpr, error) {
+	x := &xpathExpr{source: expr}
+	rest := strings.TrimSpace(expr)
+	if rest == "" {
+		return nil, fmt.Errorf("invalid xpath: empty expression")
+	}
+
+	descendant := !strings.HasPrefix(rest, "/")
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			descendant = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "/"):
+			rest = rest[1:]
+		}
+
+		end := xpathStepEnd(rest)
+		step, err := parseXPathStep(rest[:end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpath \"%s\": %s", expr, err)
+		}
+		step.descendant = descendant
+		x.steps = append(x.steps, step)
+
+		descendant = false
+		rest = rest[end:]
+	}
+
+	for i, s := range x.steps[:len(x.steps)-1] {
+		if s.name == "text()" || strings.HasPrefix(s.name, "@") {
+			return nil, fmt.Errorf("invalid xpath \"%s\": %s must be the last step, not step %d", expr, s.name, i+1)
+		}
+	}
+
+	return x, nil
+}
+
+// Returns the length of the step at the start of s, skipping slashes inside predicates and quotes
+func xpathStepEnd(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '/' && depth == 0:
+			return i
+		}
+	}
+	return len(s)
+}
+
+func parseXPathStep(s string) (xpathStep, error) {
+	var step xpathStep
+
+	i := strings.Index(s, "[")
+	if i < 0 {
+		i = len(s)
+	}
+	step.name = strings.TrimSpace(s[:i])
+	if step.name == "" {
+		return step, fmt.Errorf("empty step")
+	}
+
+	for rest := s[i:]; rest != ""; {
+		if rest[0] != '[' {
+			return step, fmt.Errorf("unexpected \"%s\"", rest)
+		}
+		end := xpathPredEnd(rest)
+		if end < 0 {
+			return step, fmt.Errorf("unterminated predicate in \"%s\"", s)
+		}
+		pred, err := parseXPathPred(strings.TrimSpace(rest[1:end]))
+		if err != nil {
+			return step, err
+		}
+		step.preds = append(step.preds, pred)
+		rest = rest[end+1:]
+	}
+
+	return step, nil
+}
+
+// Returns the index of the "]" closing the predicate at the start of s, skipping quoted values, or -1
+func xpathPredEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseXPathPred(s string) (xpathPred, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 {
+			return xpathPred{}, fmt.Errorf("invalid position [%d]", n)
+		}
+		return xpathPred{index: n}, nil
+	}
+
+	eq := strings.Index(s, "=")
+	if eq < 0 {
+		return xpathPred{name: s}, nil
+	}
+
+	value := strings.TrimSpace(s[eq+1:])
+	if len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+		return xpathPred{}, fmt.Errorf("predicate value %s must be quoted", value)
+	}
+	value = value[1 : len(value)-1]
+
+	return xpathPred{name: strings.TrimSpace(s[:eq]), value: &value}, nil
+}
+
+/*
+	Evaluates the expression against a document and returns the selected nodes in document order. Attributes
+	selected by a final @attr step are returned as leaf nodes named "@attr" whose parent is the owning element.
+*/
+func (x *xpathExpr) Select(root *xmlNode) []*xmlNode {
+	doc := &xmlNode{Children: []*xmlNode{root}}
+	nodes := []*xmlNode{doc}
+
+	for _, step := range x.steps {
+		var next []*xmlNode
+		seen := make(map[*xmlNode]bool)
+		add := func(n *xmlNode) {
+			if n != nil && n != doc && !seen[n] {
+				seen[n] = true
+				next = append(next, n)
+			}
+		}
+
+		for _, ctx := range nodes {
+			candidates := []*xmlNode{ctx}
+			if step.descendant {
+				candidates = ctx.descendants()
+			}
+			for _, n := range candidates {
+				switch {
+				case step.name == "..":
+					add(n.Parent)
+				case step.name == "text()":
+					add(n)
+				case strings.HasPrefix(step.name, "@"):
+					for _, a := range n.Attrs {
+						if step.name == "@*" || step.name[1:] == a.Name.Local {
+							add(&xmlNode{Name: "@" + a.Name.Local, Text: a.Value, Parent: n})
+						}
+					}
+				default:
+					for _, child := range n.Children {
+						if step.matches(child) {
+							add(child)
+						}
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+
+	return nodes
+}
+
+// Returns the node followed by all of its descendant elements, in document order
+func (n *xmlNode) descendants() []*xmlNode {
+	nodes := []*xmlNode{n}
+	for _, child := range n.Children {
+		nodes = append(nodes, child.descendants()...)
+	}
+	return nodes
+}
+
+func (s xpathStep) matches(n *xmlNode) bool {
+	if s.name != "*" && s.name != n.Name {
+		return false
+	}
+
+	for _, p := range s.preds {
+		if p.index > 0 {
+			siblings := []*xmlNode{n}
+			if n.Parent != nil {
+				siblings = n.Parent.Children
+			}
+			position := 0
+			for _, sibling := range siblings {
+				if s.name == "*" || sibling.Name == n.Name {
+					position++
+				}
+				if sibling == n {
+					break
+				}
+			}
+			if position != p.index {
+				return false
+			}
+			continue
+		}
+
+		if !p.matches(n) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p xpathPred) matches(n *xmlNode) bool {
+	equals := func(v string) bool {
+		return p.value == nil || strings.TrimSpace(v) == *p.value
+	}
+
+	switch {
+	case p.name == "text()":
+		return equals(n.Text)
+	case strings.HasPrefix(p.name, "@"):
+		for _, a := range n.Attrs {
+			if a.Name.Local == p.name[1:] && equals(a.Value) {
+				return true
+			}
+		}
+	default:
+		for _, child := range n.Children {
+			if child.Name == p.name && equals(child.Text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
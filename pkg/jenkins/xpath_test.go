@@ -0,0 +1,90 @@
+package jenkins
+
+import (
+	"reflect"
+	"testing"
+)
+
+const xpathTestConfig = `<?xml version='1.1' encoding='UTF-8'?>
+<project>
+  <description>build [main]</description>
+  <scm class="hudson.plugins.git.GitSCM" plugin="git@4.0">
+    <userRemoteConfigs>
+      <hudson.plugins.git.UserRemoteConfig>
+        <url>https://github.com/acme/app.git</url>
+        <credentialsId>gh-token</credentialsId>
+      </hudson.plugins.git.UserRemoteConfig>
+    </userRemoteConfigs>
+  </scm>
+  <builders>
+    <hudson.tasks.Shell><command>make</command></hudson.tasks.Shell>
+    <hudson.tasks.Shell><command>make test</command></hudson.tasks.Shell>
+  </builders>
+</project>`
+
+func TestCompileXPath(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"//url", false},
+		{"/project/scm/@class", false},
+		{"//*[@plugin='git@4.0']", false},
+		{"//description[text()='a]b']", false},
+		{"//description[text()=\"a/b\"]/text()", false},
+		{"", true},
+		{"//url[", true},
+		{"//url[0]", true},
+		{"//url[text()=unquoted]", true},
+		{"//@class/url", true},
+		{"//text()/url", true},
+	}
+
+	for _, tt := range tests {
+		_, err := compileXPath(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("compileXPath(%q) error = %v, want error %t", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestXPathSelect(t *testing.T) {
+	root, err := parseXML(xpathTestConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"//url", []string{"https://github.com/acme/app.git"}},
+		{"url", []string{"https://github.com/acme/app.git"}},
+		{"/project/scm/@class", []string{"hudson.plugins.git.GitSCM"}},
+		{"/project/url", nil},
+		{"//command", []string{"make", "make test"}},
+		{"//hudson.tasks.Shell[2]/command", []string{"make test"}},
+		{"//builders/*[1]/command", []string{"make"}},
+		{"//hudson.tasks.Shell[command='make test']/command", []string{"make test"}},
+		{"//command[text()='make']", []string{"make"}},
+		{"//*[@plugin='git@4.0']/userRemoteConfigs//credentialsId", []string{"gh-token"}},
+		{"//credentialsId/../url", []string{"https://github.com/acme/app.git"}},
+		{"//description[text()='build [main]']", []string{"build [main]"}},
+		{"//description[text()='build [other]']", nil},
+	}
+
+	for _, tt := range tests {
+		x, err := compileXPath(tt.expr)
+		if err != nil {
+			t.Errorf("compileXPath(%q): %s", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, n := range x.Select(root) {
+			got = append(got, n.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Select(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}